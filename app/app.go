@@ -11,17 +11,18 @@ import (
 	"bitbucket.org/toggly/toggly-server/storage"
 	"github.com/go-chi/chi"
 	"github.com/go-chi/chi/middleware"
-	dbStore "github.com/nodely/go-mongo-store"
 	"github.com/op/go-logging"
 	"gopkg.in/toggly/go-utils.v2"
 )
 
 // Toggly struct
 type Toggly struct {
-	Dbs    *dbStore.DbStorage
-	Ctx    context.Context
-	Config *models.Config
-	Logger *logging.Logger
+	Storage storage.Storage
+	Events  *service.Events
+	JWT     *JWTAuth
+	Ctx     context.Context
+	Config  *models.Config
+	Logger  *logging.Logger
 }
 
 // Run Toggly App
@@ -37,7 +38,10 @@ func (t *Toggly) Run() {
 	}
 	go func() {
 		<-t.Ctx.Done()
-		if err := srv.Shutdown(t.Ctx); err != nil {
+		// streams are closed by app context, let regular requests finish
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := srv.Shutdown(ctx); err != nil {
 			log.Error("REST stop error")
 		}
 		log.Info("REST server stopped")
@@ -49,22 +53,46 @@ func (t *Toggly) Run() {
 
 // Router returns router configuration
 func (t *Toggly) Router(basePath string) chi.Router {
+	if t.Events == nil {
+		t.Events = service.NewEvents(1000)
+	}
 	router := chi.NewRouter()
 	router.Use(utils.RequestIDCtx)
+	router.Use(RequestIDCtx)
 	router.Use(middleware.RealIP)
 	router.Use(middleware.Recoverer)
-	router.Use(middleware.Throttle(1000))
-	router.Use(middleware.Timeout(60 * time.Second))
 	router.Use(middleware.Heartbeat("/ping"))
 	router.Use(middleware.RequestLogger(&utils.StructuredLogger{Logger: t.Logger, R: nil}))
-	if t.Config.MultiUserMode {
-		router.Use(OwnerCtx(""))
+	router.Use(SDKKeyCtx(&service.SDKKey{
+		Storage: t.Storage,
+		Ctx:     t.Ctx,
+		Config:  t.Config,
+		Logger:  t.Logger,
+	}))
+	router.Route(basePath, t.versions)
+	return router
+}
+
+// authentication returns owner resolving middlewares, organization from header replaces resolved owner
+func (t *Toggly) authentication(users *service.User, orgs *service.Organization) []func(http.Handler) http.Handler {
+	var mws []func(http.Handler) http.Handler
+	if t.JWT != nil {
+		t.Logger.Info("JWT authentication is enabled")
+		mws = append(mws, t.JWT.Ctx)
+	} else if t.isSessionMode() {
+		t.Logger.Info("Session authentication is enabled")
+		mws = append(mws, CSRFHeader, SessionCtx(users))
+	} else if t.Config.MultiUserMode {
+		mws = append(mws, OwnerCtx(""), UserCtx(true))
 	} else {
 		t.Logger.Info("Single user mode is enabled")
-		router.Use(OwnerCtx("NO_OWNER_ID_MODE"))
+		mws = append(mws, OwnerCtx("NO_OWNER_ID_MODE"), UserCtx(false))
 	}
-	router.Route(basePath, t.versions)
-	return router
+	return append(mws, OrganizationCtx(orgs))
+}
+
+func (t *Toggly) isSessionMode() bool {
+	return t.Config.Auth != nil && t.Config.Auth.Mode == models.AuthModeSession
 }
 
 // versions for routing
@@ -73,20 +101,254 @@ func (t *Toggly) versions(router chi.Router) {
 	router.Route("/v1", t.v1)
 }
 
-// routes for API v1
+// routes for API v1, streams live longer than request timeout so they are
+// mounted aside of throttled and timed out routes
 func (t *Toggly) v1(router chi.Router) {
-	router.Mount("/project", (&ProjectEndpoints{
-		Dbs:    t.Dbs,
-		Ctx:    t.Ctx,
-		Config: t.Config,
-		Logger: t.Logger,
-		Service: &service.Project{
-			Storage: &storage.MongoStorage{
-				Dbs: t.Dbs,
+	spec := NewOpenAPI(t.isSessionMode())
+	users := &service.User{
+		Storage: t.Storage,
+		Ctx:     t.Ctx,
+		Config:  t.Config,
+		Logger:  t.Logger,
+	}
+	orgs := &service.Organization{
+		Storage: t.Storage,
+		Ctx:     t.Ctx,
+		Config:  t.Config,
+		Logger:  t.Logger,
+	}
+	invitations := &service.Invitation{
+		Storage: t.Storage,
+		Ctx:     t.Ctx,
+		Config:  t.Config,
+		Logger:  t.Logger,
+	}
+	auth := t.authentication(users, orgs)
+	router.Group(func(streams chi.Router) {
+		streams.Use(auth...)
+		t.streams(streams)
+	})
+
+	limited := chi.NewRouter()
+	limited.Use(middleware.Throttle(1000))
+	limited.Use(middleware.Timeout(60 * time.Second))
+	limited.Get("/openapi.json", spec.ServeHTTP)
+	// account endpoints work before user is known
+	if t.isSessionMode() {
+		limited.With(CSRFHeader, ValidateRequest(spec)).Mount("/auth", (&AuthEndpoints{
+			Ctx:     t.Ctx,
+			Config:  t.Config,
+			Logger:  t.Logger,
+			Service: users,
+		}).Routes())
+	}
+	limited.Group(func(api chi.Router) {
+		api.Use(auth...)
+		api.Use(ValidateRequest(spec))
+		t.api(api, orgs, invitations)
+	})
+	router.Mount("/", limited)
+}
+
+// api routes require resolved owner
+func (t *Toggly) api(router chi.Router, orgs *service.Organization, invitations *service.Invitation) {
+	audit := &service.Audit{
+		Storage: t.Storage,
+		Ctx:     t.Ctx,
+		Config:  t.Config,
+		Logger:  t.Logger,
+	}
+	access := &service.Access{
+		Storage: t.Storage,
+		Ctx:     t.Ctx,
+		Config:  t.Config,
+		Logger:  t.Logger,
+	}
+	changes := &service.Change{
+		Storage: t.Storage,
+		Events:  t.Events,
+		Ctx:     t.Ctx,
+		Config:  t.Config,
+		Logger:  t.Logger,
+	}
+	objects := &service.Object{
+		Storage: t.Storage,
+		Events:  t.Events,
+		Ctx:     t.Ctx,
+		Config:  t.Config,
+		Logger:  t.Logger,
+	}
+	// management API is not available with SDK keys
+	router.Group(func(admin chi.Router) {
+		admin.Use(DenySDKKey)
+		admin.Mount("/org", (&OrganizationEndpoints{
+			Ctx:         t.Ctx,
+			Config:      t.Config,
+			Logger:      t.Logger,
+			Service:     orgs,
+			Invitations: invitations,
+		}).Routes())
+		admin.Mount("/invitation", (&InvitationEndpoints{
+			Ctx:     t.Ctx,
+			Config:  t.Config,
+			Logger:  t.Logger,
+			Service: invitations,
+		}).Routes())
+		admin.Mount("/project", (&ProjectEndpoints{
+			Ctx:    t.Ctx,
+			Config: t.Config,
+			Logger: t.Logger,
+			Service: &service.Project{
+				Storage: t.Storage,
+				Ctx:     t.Ctx,
+				Config:  t.Config,
+				Logger:  t.Logger,
+			},
+			Invitations: invitations,
+			Access:      access,
+			Audit:       audit,
+		}).Routes())
+		admin.Mount("/project/{ProjectCode}/env", (&EnvironmentEndpoints{
+			Ctx:    t.Ctx,
+			Config: t.Config,
+			Logger: t.Logger,
+			Service: &service.Environment{
+				Storage: t.Storage,
+				Ctx:     t.Ctx,
+				Config:  t.Config,
+				Logger:  t.Logger,
 			},
+			Access: access,
+			Audit:  audit,
+		}).Routes())
+		admin.Mount("/project/{ProjectCode}/env/{EnvCode}/param", (&ParameterEndpoints{
 			Ctx:    t.Ctx,
 			Config: t.Config,
 			Logger: t.Logger,
+			Service: &service.Parameter{
+				Storage: t.Storage,
+				Events:  t.Events,
+				Ctx:     t.Ctx,
+				Config:  t.Config,
+				Logger:  t.Logger,
+			},
+			Access:  access,
+			Changes: changes,
+			Audit:   audit,
+		}).Routes())
+		admin.Mount("/project/{ProjectCode}/package", (&PackageEndpoints{
+			Ctx:    t.Ctx,
+			Config: t.Config,
+			Logger: t.Logger,
+			Service: &service.Package{
+				Storage: t.Storage,
+				Events:  t.Events,
+				Ctx:     t.Ctx,
+				Config:  t.Config,
+				Logger:  t.Logger,
+			},
+			Access: access,
+			Audit:  audit,
+		}).Routes())
+		admin.Mount("/project/{ProjectCode}/object", (&ObjectEndpoints{
+			Ctx:     t.Ctx,
+			Config:  t.Config,
+			Logger:  t.Logger,
+			Service: objects,
+			Access:  access,
+			Changes: changes,
+			Audit:   audit,
+		}).Routes())
+		admin.Mount("/audit", (&AuditEndpoints{
+			Ctx:     t.Ctx,
+			Config:  t.Config,
+			Logger:  t.Logger,
+			Service: audit,
+			Access:  access,
+		}).Routes())
+		admin.Mount("/project/{ProjectCode}/env/{EnvCode}/promote", (&PromotionEndpoints{
+			Ctx:    t.Ctx,
+			Config: t.Config,
+			Logger: t.Logger,
+			Service: &service.Promotion{
+				Storage: t.Storage,
+				Events:  t.Events,
+				Ctx:     t.Ctx,
+				Config:  t.Config,
+				Logger:  t.Logger,
+			},
+			Access:  access,
+			Changes: changes,
+			Audit:   audit,
+		}).Routes())
+		admin.Mount("/project/{ProjectCode}/env/{EnvCode}/key", (&SDKKeyEndpoints{
+			Ctx:    t.Ctx,
+			Config: t.Config,
+			Logger: t.Logger,
+			Service: &service.SDKKey{
+				Storage: t.Storage,
+				Ctx:     t.Ctx,
+				Config:  t.Config,
+				Logger:  t.Logger,
+			},
+			Access: access,
+		}).Routes())
+		admin.Mount("/project/{ProjectCode}/change", (&ChangeEndpoints{
+			Ctx:     t.Ctx,
+			Config:  t.Config,
+			Logger:  t.Logger,
+			Service: changes,
+			Objects: objects,
+			Access:  access,
+			Audit:   audit,
+		}).Routes())
+	})
+	router.Mount("/evaluate", (&EvaluationEndpoints{
+		Ctx:    t.Ctx,
+		Config: t.Config,
+		Logger: t.Logger,
+		Service: &service.Evaluation{
+			Storage: t.Storage,
+			Ctx:     t.Ctx,
+			Config:  t.Config,
+			Logger:  t.Logger,
+		},
+		Access: access,
+	}).Routes())
+}
+
+// streams routes push configuration changes over long living connections
+func (t *Toggly) streams(router chi.Router) {
+	access := &service.Access{
+		Storage: t.Storage,
+		Ctx:     t.Ctx,
+		Config:  t.Config,
+		Logger:  t.Logger,
+	}
+	router.Mount("/project/{ProjectCode}/env/{EnvCode}/stream", (&StreamEndpoints{
+		Ctx:    t.Ctx,
+		Config: t.Config,
+		Logger: t.Logger,
+		Events: t.Events,
+		Service: &service.Evaluation{
+			Storage: t.Storage,
+			Ctx:     t.Ctx,
+			Config:  t.Config,
+			Logger:  t.Logger,
+		},
+		Access: access,
+	}).Routes())
+	router.Mount("/ws", (&WebSocketEndpoints{
+		Ctx:    t.Ctx,
+		Config: t.Config,
+		Logger: t.Logger,
+		Events: t.Events,
+		Service: &service.Evaluation{
+			Storage: t.Storage,
+			Ctx:     t.Ctx,
+			Config:  t.Config,
+			Logger:  t.Logger,
 		},
+		Access: access,
 	}).Routes())
 }
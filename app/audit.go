@@ -0,0 +1,102 @@
+package app
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"bitbucket.org/toggly/toggly-server/models"
+	"bitbucket.org/toggly/toggly-server/service"
+	"github.com/go-chi/chi"
+	"github.com/op/go-logging"
+)
+
+// AuditEndpoints API struct
+type AuditEndpoints struct {
+	Ctx     context.Context
+	Config  *models.Config
+	Logger  *logging.Logger
+	Service *service.Audit
+	Access  *service.Access
+}
+
+// Routes returns api endpoints
+func (a *AuditEndpoints) Routes() chi.Router {
+	router := chi.NewRouter()
+	router.Group(func(group chi.Router) {
+		group.Get("/", a.list)
+	})
+	return router
+}
+
+func (a *AuditEndpoints) list(w http.ResponseWriter, r *http.Request) {
+	log := GetLogger(r)
+	owner := models.OwnerFromContext(r)
+
+	filter, err := auditFilter(r)
+	if err != nil {
+		log.Errorf("Can't parse audit filter: %s", err.Error())
+		models.ErrorResponseWithStatus(w, r, err, http.StatusBadRequest)
+		return
+	}
+
+	// members see audit of their projects only
+	if err := a.Access.Check(owner, models.UserFromContext(r), filter.Project, "", models.PermissionRead); err != nil {
+		log.Errorf("Access denied: %s", err.Error())
+		models.ErrorResponse(w, r, err)
+		return
+	}
+
+	recs := a.Service.List(owner, filter)
+
+	log.Debugf("Audit.list: %d items found", len(recs))
+	models.JSONResponse(w, r, recs)
+}
+
+// auditFilter reads filter from query string, from/to are RFC 3339 times
+func auditFilter(r *http.Request) (*models.AuditFilter, error) {
+	query := r.URL.Query()
+	filter := &models.AuditFilter{
+		Entity:      query.Get("entity"),
+		Action:      query.Get("action"),
+		Project:     query.Get("project"),
+		Environment: query.Get("environment"),
+		Code:        query.Get("code"),
+		Instance:    query.Get("instance"),
+		RequestID:   query.Get("requestId"),
+		User:        query.Get("user"),
+		Limit:       100,
+	}
+	var err error
+	if from := query.Get("from"); from != "" {
+		if filter.From, err = time.Parse(time.RFC3339, from); err != nil {
+			return nil, err
+		}
+	}
+	if to := query.Get("to"); to != "" {
+		if filter.To, err = time.Parse(time.RFC3339, to); err != nil {
+			return nil, err
+		}
+	}
+	if limit := query.Get("limit"); limit != "" {
+		if filter.Limit, err = strconv.ParseInt(limit, 10, 64); err != nil {
+			return nil, err
+		}
+	}
+	return filter, nil
+}
+
+// auditRecord returns audit record of mutation made by request
+func auditRecord(r *http.Request, entity string, action string, project string, env string, code string) *models.AuditRecord {
+	return &models.AuditRecord{
+		OwnerID:     models.OwnerFromContext(r),
+		RequestID:   models.RequestIDFromContext(r),
+		User:        models.UserFromContext(r),
+		Entity:      entity,
+		Action:      action,
+		Project:     project,
+		Environment: env,
+		Code:        code,
+	}
+}
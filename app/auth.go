@@ -0,0 +1,183 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+
+	"bitbucket.org/toggly/toggly-server/models"
+	"bitbucket.org/toggly/toggly-server/service"
+	"github.com/go-chi/chi"
+	"github.com/op/go-logging"
+	"gopkg.in/session.v3"
+)
+
+// sessionUserKey keeps user id in session
+const sessionUserKey = "user_id"
+
+// AuthEndpoints API struct
+type AuthEndpoints struct {
+	Ctx     context.Context
+	Config  *models.Config
+	Logger  *logging.Logger
+	Service *service.User
+}
+
+// Routes returns api endpoints
+func (a *AuthEndpoints) Routes() chi.Router {
+	router := chi.NewRouter()
+	router.Group(func(group chi.Router) {
+		group.Post("/register", a.register)
+		group.Post("/login", a.login)
+		group.Post("/logout", a.logout)
+		group.Get("/me", a.me)
+	})
+	return router
+}
+
+func (a *AuthEndpoints) register(w http.ResponseWriter, r *http.Request) {
+	log := GetLogger(r)
+
+	data, err := readCredentials(r)
+	if err != nil {
+		log.Error("Can't parse request body")
+		models.ErrorResponseWithStatus(w, r, err, http.StatusBadRequest)
+		return
+	}
+
+	resp, err := a.Service.Register(*data)
+	if err != nil {
+		log.Errorf("User.Service.Register: %s", err.Error())
+		models.ErrorResponse(w, r, err)
+		return
+	}
+
+	if err := startSession(w, r, resp); err != nil {
+		log.Errorf("Can't start session: %s", err.Error())
+		models.ErrorResponseWithStatus(w, r, err, http.StatusInternalServerError)
+		return
+	}
+
+	log.Debugf("User [%s] registered", resp.Email)
+
+	models.JSONResponse(w, r, resp)
+}
+
+func (a *AuthEndpoints) login(w http.ResponseWriter, r *http.Request) {
+	log := GetLogger(r)
+
+	data, err := readCredentials(r)
+	if err != nil {
+		log.Error("Can't parse request body")
+		models.ErrorResponseWithStatus(w, r, err, http.StatusBadRequest)
+		return
+	}
+
+	resp, err := a.Service.Login(*data)
+	if err != nil {
+		log.Errorf("User.Service.Login: %s", err.Error())
+		models.ErrorResponse(w, r, err)
+		return
+	}
+
+	if err := startSession(w, r, resp); err != nil {
+		log.Errorf("Can't start session: %s", err.Error())
+		models.ErrorResponseWithStatus(w, r, err, http.StatusInternalServerError)
+		return
+	}
+
+	log.Debugf("User [%s] logged in", resp.Email)
+
+	models.JSONResponse(w, r, resp)
+}
+
+func (a *AuthEndpoints) logout(w http.ResponseWriter, r *http.Request) {
+	log := GetLogger(r)
+
+	if err := session.Destroy(r.Context(), w, r); err != nil {
+		log.Errorf("Can't destroy session: %s", err.Error())
+		models.ErrorResponseWithStatus(w, r, err, http.StatusInternalServerError)
+		return
+	}
+
+	models.NoContentResponse(w, r)
+}
+
+func (a *AuthEndpoints) me(w http.ResponseWriter, r *http.Request) {
+	log := GetLogger(r)
+
+	resp, err := sessionUser(w, r, a.Service)
+	if err != nil {
+		log.Errorf("Session user: %s", err.Error())
+		models.ErrorResponse(w, r, err)
+		return
+	}
+
+	models.JSONResponse(w, r, resp)
+}
+
+// SessionCtx adds session user to context as owner,
+// requests authorized with SDK key don't need session
+func SessionCtx(users *service.User) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			log := GetLogger(r)
+			if models.SDKKeyFromContext(r) != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+			user, err := sessionUser(w, r, users)
+			if err != nil {
+				log.Errorf("Session user: %s", err.Error())
+				models.ErrorResponse(w, r, err)
+				return
+			}
+			ctx := r.Context()
+			ctx = context.WithValue(ctx, models.CtxValueOwner, user.ID.Hex())
+			ctx = context.WithValue(ctx, models.CtxValueUser, user.ID.Hex())
+			ctx = context.WithValue(ctx, models.CtxValueAuth, user)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		}
+		return http.HandlerFunc(fn)
+	}
+}
+
+// sessionUser returns user of current session
+func sessionUser(w http.ResponseWriter, r *http.Request, users *service.User) (*models.User, error) {
+	store, err := session.Start(r.Context(), w, r)
+	if err != nil {
+		return nil, models.ErrInternalServer(err.Error())
+	}
+	id, ok := store.Get(sessionUserKey)
+	if !ok {
+		return nil, models.ErrUnauthorized("Session is not authenticated")
+	}
+	user, err := users.Get(id.(string))
+	if err != nil {
+		return nil, models.ErrUnauthorized("Session user is not found")
+	}
+	return user, nil
+}
+
+// startSession binds new session to user, previous session id is dropped
+func startSession(w http.ResponseWriter, r *http.Request, user *models.User) error {
+	store, err := session.Refresh(r.Context(), w, r)
+	if err != nil {
+		return err
+	}
+	store.Set(sessionUserKey, user.ID.Hex())
+	return store.Save()
+}
+
+func readCredentials(r *http.Request) (*models.Credentials, error) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+	var data models.Credentials
+	if err := json.Unmarshal(body, &data); err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
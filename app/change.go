@@ -0,0 +1,199 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+
+	"bitbucket.org/toggly/toggly-server/models"
+	"bitbucket.org/toggly/toggly-server/service"
+	"github.com/go-chi/chi"
+	"github.com/op/go-logging"
+)
+
+// ChangeEndpoints API struct
+type ChangeEndpoints struct {
+	Ctx     context.Context
+	Config  *models.Config
+	Logger  *logging.Logger
+	Service *service.Change
+	Objects *service.Object
+	Access  *service.Access
+	Audit   *service.Audit
+}
+
+// Routes returns api endpoints
+func (a *ChangeEndpoints) Routes() chi.Router {
+	router := chi.NewRouter()
+	router.Group(func(group chi.Router) {
+		group.Use(ProjectAccess(a.Access, models.PermissionWrite))
+		group.Get("/", a.list)
+		group.Get("/{ChangeID}", a.get)
+		group.Post("/{ChangeID}/comment", a.comment)
+	})
+	// only admins decide on changes
+	router.Group(func(group chi.Router) {
+		group.Use(ProjectAccess(a.Access, models.PermissionManage))
+		group.Post("/{ChangeID}/approve", a.approve)
+		group.Post("/{ChangeID}/reject", a.reject)
+		group.Post("/{ChangeID}/apply", a.apply)
+	})
+	return router
+}
+
+func (a *ChangeEndpoints) list(w http.ResponseWriter, r *http.Request) {
+	log := GetLogger(r)
+	owner := models.OwnerFromContext(r)
+	project := chi.URLParam(r, "ProjectCode")
+	query := r.URL.Query()
+
+	recs, err := a.Service.List(owner, project, query.Get("env"), query.Get("status"))
+	if err != nil {
+		log.Errorf("Change.Service.List: %s", err.Error())
+		models.ErrorResponse(w, r, err)
+		return
+	}
+
+	log.Debugf("Change.list: %d items found", len(recs))
+	models.JSONResponse(w, r, recs)
+}
+
+func (a *ChangeEndpoints) get(w http.ResponseWriter, r *http.Request) {
+	log := GetLogger(r)
+	owner := models.OwnerFromContext(r)
+	project := chi.URLParam(r, "ProjectCode")
+	id := chi.URLParam(r, "ChangeID")
+
+	resp, err := a.Service.Get(owner, project, id)
+	if err != nil {
+		log.Errorf("Change.Service.Get: %s", err.Error())
+		models.ErrorResponse(w, r, err)
+		return
+	}
+
+	log.Debugf("Change: %+v", resp)
+
+	models.JSONResponse(w, r, resp)
+}
+
+func (a *ChangeEndpoints) comment(w http.ResponseWriter, r *http.Request) {
+	log := GetLogger(r)
+	owner := models.OwnerFromContext(r)
+	user := models.UserFromContext(r)
+	project := chi.URLParam(r, "ProjectCode")
+	id := chi.URLParam(r, "ChangeID")
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		log.Error("Can't read request body")
+		models.ErrorResponseWithStatus(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	var data models.ChangeComment
+	if err := json.Unmarshal(body, &data); err != nil {
+		log.Error("Can't parse request body")
+		models.ErrorResponseWithStatus(w, r, err, http.StatusBadRequest)
+		return
+	}
+
+	resp, err := a.Service.Comment(owner, user, project, id, data.Text)
+	if err != nil {
+		log.Errorf("Change.Service.Comment: %s", err.Error())
+		models.ErrorResponse(w, r, err)
+		return
+	}
+
+	log.Debugf("Change: %+v", resp)
+
+	models.JSONResponse(w, r, resp)
+}
+
+func (a *ChangeEndpoints) approve(w http.ResponseWriter, r *http.Request) {
+	log := GetLogger(r)
+	owner := models.OwnerFromContext(r)
+	user := models.UserFromContext(r)
+	project := chi.URLParam(r, "ProjectCode")
+	id := chi.URLParam(r, "ChangeID")
+
+	resp, err := a.Service.Approve(owner, user, project, id)
+	if err != nil {
+		log.Errorf("Change.Service.Approve: %s", err.Error())
+		models.ErrorResponse(w, r, err)
+		return
+	}
+
+	log.Debugf("Change: %+v", resp)
+
+	models.JSONResponse(w, r, resp)
+}
+
+func (a *ChangeEndpoints) reject(w http.ResponseWriter, r *http.Request) {
+	log := GetLogger(r)
+	owner := models.OwnerFromContext(r)
+	user := models.UserFromContext(r)
+	project := chi.URLParam(r, "ProjectCode")
+	id := chi.URLParam(r, "ChangeID")
+
+	resp, err := a.Service.Reject(owner, user, project, id)
+	if err != nil {
+		log.Errorf("Change.Service.Reject: %s", err.Error())
+		models.ErrorResponse(w, r, err)
+		return
+	}
+
+	log.Debugf("Change: %+v", resp)
+
+	models.JSONResponse(w, r, resp)
+}
+
+func (a *ChangeEndpoints) apply(w http.ResponseWriter, r *http.Request) {
+	log := GetLogger(r)
+	owner := models.OwnerFromContext(r)
+	project := chi.URLParam(r, "ProjectCode")
+	id := chi.URLParam(r, "ChangeID")
+
+	// override state before change is kept for audit
+	var before *models.Override
+	change, err := a.Service.Get(owner, project, id)
+	if err == nil && change.Entity == models.ChangeEntityOverride {
+		before = objectOverride(a.Objects, owner, project, change.Instance, change.Environment, change.Code)
+	}
+
+	resp, applied, err := a.Service.Apply(owner, project, id)
+	// partially applied promotion is audited as well
+	for _, item := range applied {
+		a.Audit.Record(auditRecord(r, models.AuditEntityParameter, item.Action, project, resp.Environment, item.Code), item.Before, item.After)
+	}
+	if resp != nil && resp.Entity == models.ChangeEntityOverride && resp.Status == models.ChangeStatusApplied {
+		after := objectOverride(a.Objects, owner, project, resp.Instance, resp.Environment, resp.Code)
+		a.Audit.Record(overrideRecord(r, resp.Action, project, resp.Environment, resp.Instance, resp.Code, before), before, after)
+	}
+	if err != nil {
+		log.Errorf("Change.Service.Apply: %s", err.Error())
+		models.ErrorResponse(w, r, err)
+		return
+	}
+
+	log.Debugf("Change: %+v", resp)
+
+	models.JSONResponse(w, r, resp)
+}
+
+// proposeChange creates change request instead of changing protected environment
+func proposeChange(w http.ResponseWriter, r *http.Request, svc *service.Change, project string, env string, data models.ChangeRequest) {
+	log := GetLogger(r)
+	owner := models.OwnerFromContext(r)
+	user := models.UserFromContext(r)
+
+	resp, err := svc.Create(owner, user, project, env, data)
+	if err != nil {
+		log.Errorf("Change.Service.Create: %s", err.Error())
+		models.ErrorResponse(w, r, err)
+		return
+	}
+
+	log.Debugf("Change: %+v", resp)
+
+	models.AcceptedResponse(w, r, resp)
+}
@@ -0,0 +1,162 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+
+	"bitbucket.org/toggly/toggly-server/models"
+	"bitbucket.org/toggly/toggly-server/service"
+	"github.com/go-chi/chi"
+	"github.com/op/go-logging"
+)
+
+// EnvironmentEndpoints API struct
+type EnvironmentEndpoints struct {
+	Ctx     context.Context
+	Config  *models.Config
+	Logger  *logging.Logger
+	Service *service.Environment
+	Access  *service.Access
+	Audit   *service.Audit
+}
+
+// Routes returns api endpoints
+func (a *EnvironmentEndpoints) Routes() chi.Router {
+	router := chi.NewRouter()
+	router.Group(func(group chi.Router) {
+		group.Use(ProjectAccess(a.Access, models.PermissionManage))
+		group.Get("/", a.list)
+		group.Post("/", a.create)
+		group.Put("/{EnvCode}", a.update)
+		group.Get("/{EnvCode}", a.get)
+		group.Delete("/{EnvCode}", a.delete)
+	})
+	return router
+}
+
+func (a *EnvironmentEndpoints) list(w http.ResponseWriter, r *http.Request) {
+	log := GetLogger(r)
+	owner := models.OwnerFromContext(r)
+	project := chi.URLParam(r, "ProjectCode")
+
+	recs, err := a.Service.List(owner, project)
+	if err != nil {
+		log.Errorf("Environment.Service.List: %s", err.Error())
+		models.ErrorResponse(w, r, err)
+		return
+	}
+
+	log.Debugf("Environment.list: %d items found", len(recs))
+	models.JSONResponse(w, r, recs)
+}
+
+func (a *EnvironmentEndpoints) create(w http.ResponseWriter, r *http.Request) {
+	log := GetLogger(r)
+	owner := models.OwnerFromContext(r)
+	project := chi.URLParam(r, "ProjectCode")
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		log.Error("Can't read request body")
+		models.ErrorResponseWithStatus(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	var data models.Environment
+	if err := json.Unmarshal(body, &data); err != nil {
+		log.Error("Can't parse request body")
+		models.ErrorResponseWithStatus(w, r, err, http.StatusBadRequest)
+		return
+	}
+
+	resp, err := a.Service.Create(owner, project, data)
+	if err != nil {
+		log.Errorf("Environment.Service.Create: %s", err.Error())
+		models.ErrorResponse(w, r, err)
+		return
+	}
+
+	a.Audit.Record(auditRecord(r, models.AuditEntityEnvironment, models.AuditActionCreate, project, "", resp.Code), nil, resp)
+
+	log.Debugf("Environment: %+v", resp)
+
+	models.JSONResponse(w, r, resp)
+}
+
+func (a *EnvironmentEndpoints) update(w http.ResponseWriter, r *http.Request) {
+	log := GetLogger(r)
+	owner := models.OwnerFromContext(r)
+	project := chi.URLParam(r, "ProjectCode")
+	code := chi.URLParam(r, "EnvCode")
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		log.Error("Can't read request body")
+		models.ErrorResponseWithStatus(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	var data models.Environment
+	if err := json.Unmarshal(body, &data); err != nil {
+		log.Error("Can't parse request body")
+		models.ErrorResponseWithStatus(w, r, err, http.StatusBadRequest)
+		return
+	}
+
+	// code is taken from URL and can't be changed
+	data.Code = code
+
+	before, _ := a.Service.Get(owner, project, code)
+
+	resp, err := a.Service.Update(owner, project, data)
+	if err != nil {
+		log.Errorf("Environment.Service.Update: %s", err.Error())
+		models.ErrorResponse(w, r, err)
+		return
+	}
+
+	a.Audit.Record(auditRecord(r, models.AuditEntityEnvironment, models.AuditActionUpdate, project, "", code), before, resp)
+
+	log.Debugf("Environment: %+v", resp)
+
+	models.JSONResponse(w, r, resp)
+}
+
+func (a *EnvironmentEndpoints) get(w http.ResponseWriter, r *http.Request) {
+	log := GetLogger(r)
+	owner := models.OwnerFromContext(r)
+	project := chi.URLParam(r, "ProjectCode")
+	code := chi.URLParam(r, "EnvCode")
+
+	resp, err := a.Service.Get(owner, project, code)
+	if err != nil {
+		log.Errorf("Environment.Service.Get: %s", err.Error())
+		models.ErrorResponse(w, r, err)
+		return
+	}
+
+	log.Debugf("Environment: %+v", resp)
+
+	models.JSONResponse(w, r, resp)
+}
+
+func (a *EnvironmentEndpoints) delete(w http.ResponseWriter, r *http.Request) {
+	log := GetLogger(r)
+	owner := models.OwnerFromContext(r)
+	project := chi.URLParam(r, "ProjectCode")
+	code := chi.URLParam(r, "EnvCode")
+
+	before, _ := a.Service.Get(owner, project, code)
+
+	if err := a.Service.Delete(owner, project, code); err != nil {
+		log.Errorf("Environment.Service.Delete: %s", err.Error())
+		models.ErrorResponse(w, r, err)
+		return
+	}
+
+	a.Audit.Record(auditRecord(r, models.AuditEntityEnvironment, models.AuditActionDelete, project, "", code), before, nil)
+
+	log.Debugf("Environment [%s] deleted", code)
+
+	models.NoContentResponse(w, r)
+}
@@ -0,0 +1,114 @@
+package app
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+
+	"bitbucket.org/toggly/toggly-server/models"
+	"bitbucket.org/toggly/toggly-server/service"
+	"github.com/go-chi/chi"
+	"github.com/op/go-logging"
+)
+
+// EvaluationEndpoints API struct
+type EvaluationEndpoints struct {
+	Ctx     context.Context
+	Config  *models.Config
+	Logger  *logging.Logger
+	Service *service.Evaluation
+	Access  *service.Access
+}
+
+// Routes returns api endpoints,
+// project and environment are taken from X-Toggly-Project and X-Toggly-Environment headers.
+// Evaluation context comes as query parameters on GET or as request body on POST
+// in form of {"instance": "id", "context": {"attribute": "value"}}
+func (a *EvaluationEndpoints) Routes() chi.Router {
+	router := chi.NewRouter()
+	router.Use(ProjectCtx)
+	router.Use(EnvironmentCtx)
+	router.Use(EvaluationAccess(a.Access))
+	router.Group(func(group chi.Router) {
+		group.Get("/", a.values)
+		group.Post("/", a.values)
+		group.Get("/{PackageCode}", a.values)
+		group.Post("/{PackageCode}", a.values)
+	})
+	return router
+}
+
+func (a *EvaluationEndpoints) values(w http.ResponseWriter, r *http.Request) {
+	log := GetLogger(r)
+	owner := models.OwnerFromContext(r)
+	project := models.ProjectFromContext(r)
+	env := models.EnvironmentFromContext(r)
+	pkg := chi.URLParam(r, "PackageCode")
+
+	data, err := evaluationRequest(r)
+	if err != nil {
+		log.Error("Can't parse request body")
+		models.ErrorResponseWithStatus(w, r, err, http.StatusBadRequest)
+		return
+	}
+
+	resp, err := a.Service.KeyValues(models.SDKKeyFromContext(r), owner, project, env, pkg, data.Instance, data.Context)
+	if err != nil {
+		log.Errorf("Evaluation.Service.KeyValues: %s", err.Error())
+		models.ErrorResponse(w, r, err)
+		return
+	}
+
+	// let pollers skip unchanged documents
+	body, err := json.Marshal(resp)
+	if err != nil {
+		log.Error("Can't serialize values")
+		models.ErrorResponseWithStatus(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	sum := sha1.Sum(body)
+	etag := `"` + hex.EncodeToString(sum[:]) + `"`
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "no-cache")
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	log.Debugf("Evaluation.values: %d items found", len(resp))
+	models.JSONResponse(w, r, resp)
+}
+
+// EvaluationRequest struct
+type EvaluationRequest struct {
+	Instance string                 `json:"instance"`
+	Context  map[string]interface{} `json:"context"`
+}
+
+// evaluationRequest reads evaluation context from request body or query
+func evaluationRequest(r *http.Request) (*EvaluationRequest, error) {
+	data := &EvaluationRequest{Context: make(map[string]interface{})}
+	if r.Method == http.MethodPost {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			return nil, err
+		}
+		if len(body) > 0 {
+			if err := json.Unmarshal(body, data); err != nil {
+				return nil, err
+			}
+		}
+		return data, nil
+	}
+	for key, values := range r.URL.Query() {
+		if key == "instance" {
+			data.Instance = values[0]
+			continue
+		}
+		data.Context[key] = values[0]
+	}
+	return data, nil
+}
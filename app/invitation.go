@@ -0,0 +1,123 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+
+	"bitbucket.org/toggly/toggly-server/models"
+	"bitbucket.org/toggly/toggly-server/service"
+	"github.com/go-chi/chi"
+	"github.com/op/go-logging"
+)
+
+// InvitationEndpoints API struct, invitations addressed to acting user
+type InvitationEndpoints struct {
+	Ctx     context.Context
+	Config  *models.Config
+	Logger  *logging.Logger
+	Service *service.Invitation
+}
+
+// Routes returns api endpoints
+func (a *InvitationEndpoints) Routes() chi.Router {
+	router := chi.NewRouter()
+	router.Group(func(group chi.Router) {
+		group.Get("/", a.list)
+		group.Post("/accept", a.acceptToken)
+		group.Post("/decline", a.declineToken)
+		group.Post("/{InvitationID}/accept", a.accept)
+		group.Post("/{InvitationID}/decline", a.decline)
+	})
+	return router
+}
+
+func (a *InvitationEndpoints) list(w http.ResponseWriter, r *http.Request) {
+	log := GetLogger(r)
+	recs := a.Service.Pending(models.EmailFromContext(r))
+	log.Debugf("Invitation.list: %d items found", len(recs))
+	models.JSONResponse(w, r, recs)
+}
+
+func (a *InvitationEndpoints) acceptToken(w http.ResponseWriter, r *http.Request) {
+	log := GetLogger(r)
+
+	item, err := a.byToken(r)
+	if err != nil {
+		log.Errorf("Invitation.Service.ByToken: %s", err.Error())
+		models.ErrorResponse(w, r, err)
+		return
+	}
+
+	a.respond(w, r, a.Service.Accept, item)
+}
+
+func (a *InvitationEndpoints) declineToken(w http.ResponseWriter, r *http.Request) {
+	log := GetLogger(r)
+
+	item, err := a.byToken(r)
+	if err != nil {
+		log.Errorf("Invitation.Service.ByToken: %s", err.Error())
+		models.ErrorResponse(w, r, err)
+		return
+	}
+
+	a.respond(w, r, a.Service.Decline, item)
+}
+
+func (a *InvitationEndpoints) accept(w http.ResponseWriter, r *http.Request) {
+	log := GetLogger(r)
+
+	item, err := a.Service.ByEmail(models.EmailFromContext(r), chi.URLParam(r, "InvitationID"))
+	if err != nil {
+		log.Errorf("Invitation.Service.ByEmail: %s", err.Error())
+		models.ErrorResponse(w, r, err)
+		return
+	}
+
+	a.respond(w, r, a.Service.Accept, item)
+}
+
+func (a *InvitationEndpoints) decline(w http.ResponseWriter, r *http.Request) {
+	log := GetLogger(r)
+
+	item, err := a.Service.ByEmail(models.EmailFromContext(r), chi.URLParam(r, "InvitationID"))
+	if err != nil {
+		log.Errorf("Invitation.Service.ByEmail: %s", err.Error())
+		models.ErrorResponse(w, r, err)
+		return
+	}
+
+	a.respond(w, r, a.Service.Decline, item)
+}
+
+// byToken finds invitation by token from request body
+func (a *InvitationEndpoints) byToken(r *http.Request) (*models.Invitation, error) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, models.ErrInternalServer(err.Error())
+	}
+	var data models.InvitationToken
+	if err := json.Unmarshal(body, &data); err != nil {
+		return nil, models.ErrBadRequest(err.Error())
+	}
+	return a.Service.ByToken(data.Token)
+}
+
+// respond answers invitation on behalf of acting user
+func (a *InvitationEndpoints) respond(w http.ResponseWriter, r *http.Request,
+	answer func(user string, item *models.Invitation) (*models.Invitation, error), item *models.Invitation) {
+	log := GetLogger(r)
+
+	resp, err := answer(models.UserFromContext(r), item)
+	if err != nil {
+		log.Errorf("Invitation.Service: %s", err.Error())
+		models.ErrorResponse(w, r, err)
+		return
+	}
+
+	log.Debugf("Invitation [%s] is %s", resp.ID.Hex(), resp.Status)
+
+	models.JSONResponse(w, r, resp)
+}
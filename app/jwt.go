@@ -0,0 +1,164 @@
+package app
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"strings"
+
+	"bitbucket.org/toggly/toggly-server/models"
+	"github.com/dgrijalva/jwt-go"
+)
+
+// JWTAuth validates bearer tokens issued by external identity provider
+type JWTAuth struct {
+	Config *models.JWT
+	keys   map[string]*rsa.PublicKey
+}
+
+// NewJWTAuth checks configuration and loads JWKS file for RS256
+func NewJWTAuth(cfg *models.JWT) (*JWTAuth, error) {
+	if cfg == nil {
+		return nil, errors.New("jwt configuration is missed")
+	}
+	if cfg.OwnerClaim == "" {
+		cfg.OwnerClaim = "sub"
+	}
+	auth := &JWTAuth{Config: cfg}
+	switch cfg.Algorithm {
+	case jwt.SigningMethodHS256.Alg():
+		if cfg.Secret == "" {
+			return nil, errors.New("jwt secret is missed")
+		}
+	case jwt.SigningMethodRS256.Alg():
+		keys, err := loadJWKS(cfg.JWKS)
+		if err != nil {
+			return nil, err
+		}
+		auth.keys = keys
+	default:
+		return nil, fmt.Errorf("jwt algorithm [%s] is not supported", cfg.Algorithm)
+	}
+	return auth, nil
+}
+
+// Ctx adds owner, user and token claims to context,
+// requests authorized with SDK key don't need token
+func (a *JWTAuth) Ctx(next http.Handler) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		log := GetLogger(r)
+		if models.SDKKeyFromContext(r) != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		header := r.Header.Get("Authorization")
+		if !strings.HasPrefix(header, "Bearer ") {
+			log.Error("Bearer token missed")
+			models.UnauthorizedResponse(w, r)
+			return
+		}
+		claims, err := a.Parse(strings.TrimPrefix(header, "Bearer "))
+		if err != nil {
+			log.Errorf("Bearer token is invalid: %s", err.Error())
+			models.UnauthorizedResponse(w, r)
+			return
+		}
+		owner, _ := claims[a.Config.OwnerClaim].(string)
+		if owner == "" {
+			log.Errorf("Bearer token has no [%s] claim", a.Config.OwnerClaim)
+			models.UnauthorizedResponse(w, r)
+			return
+		}
+		ctx := r.Context()
+		ctx = context.WithValue(ctx, models.CtxValueOwner, owner)
+		ctx = context.WithValue(ctx, models.CtxValueAuth, claims)
+		if user, ok := claims[a.Config.UserClaim].(string); ok && a.Config.UserClaim != "" {
+			ctx = context.WithValue(ctx, models.CtxValueUser, user)
+		}
+		next.ServeHTTP(w, r.WithContext(ctx))
+	}
+	return http.HandlerFunc(fn)
+}
+
+// Parse validates token signature, expiration, issuer and audience
+func (a *JWTAuth) Parse(token string) (jwt.MapClaims, error) {
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(token, claims, func(t *jwt.Token) (interface{}, error) {
+		// algorithm is fixed by configuration, token can't choose it
+		if t.Method.Alg() != a.Config.Algorithm {
+			return nil, fmt.Errorf("unexpected signing method %s", t.Method.Alg())
+		}
+		if a.keys == nil {
+			return []byte(a.Config.Secret), nil
+		}
+		kid, _ := t.Header["kid"].(string)
+		if key, ok := a.keys[kid]; ok {
+			return key, nil
+		}
+		if len(a.keys) == 1 && kid == "" {
+			for _, key := range a.keys {
+				return key, nil
+			}
+		}
+		return nil, fmt.Errorf("unknown key id [%s]", kid)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if a.Config.Issuer != "" && !claims.VerifyIssuer(a.Config.Issuer, true) {
+		return nil, errors.New("issuer is invalid")
+	}
+	if a.Config.Audience != "" && !claims.VerifyAudience(a.Config.Audience, true) {
+		return nil, errors.New("audience is invalid")
+	}
+	return claims, nil
+}
+
+// jwk is RSA public key from JWKS document
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+func loadJWKS(path string) (map[string]*rsa.PublicKey, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var doc struct {
+		Keys []*jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(content, &doc); err != nil {
+		return nil, err
+	}
+	keys := make(map[string]*rsa.PublicKey)
+	for _, key := range doc.Keys {
+		if key.Kty != "RSA" {
+			continue
+		}
+		n, err := base64.RawURLEncoding.DecodeString(key.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(key.E)
+		if err != nil {
+			return nil, err
+		}
+		keys[key.Kid] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("jwks has no RSA keys")
+	}
+	return keys, nil
+}
@@ -1,18 +1,28 @@
 package app
 
 import (
+	"bytes"
 	"context"
+	"io/ioutil"
 	"net/http"
+	"strings"
 
 	"bitbucket.org/toggly/toggly-server/models"
+	"bitbucket.org/toggly/toggly-server/service"
+	"github.com/go-chi/chi"
 	"github.com/op/go-logging"
 	"gopkg.in/toggly/go-utils.v2"
 )
 
 // Headers
 const (
-	XTogglyOwnerID string = "X-Toggly-Owner-Id"
-	XTogglyEnvID   string = "X-Toggly-Environment"
+	XTogglyOwnerID   string = "X-Toggly-Owner-Id"
+	XTogglyEnvID     string = "X-Toggly-Environment"
+	XTogglyProjectID string = "X-Toggly-Project"
+	XTogglyUserID    string = "X-Toggly-User-Id"
+	XTogglySDKKey    string = "X-Toggly-SDK-Key"
+	XTogglyOrgID     string = "X-Toggly-Organization"
+	XRequestedWith   string = "X-Requested-With"
 )
 
 // OwnerCtx adds auth data to context
@@ -20,6 +30,11 @@ func OwnerCtx(defaultOwnerID string) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		fn := func(w http.ResponseWriter, r *http.Request) {
 			log := GetLogger(r)
+			// owner is already resolved from SDK key
+			if models.SDKKeyFromContext(r) != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
 			owner := r.Header.Get(http.CanonicalHeaderKey(XTogglyOwnerID))
 			if owner == "" && defaultOwnerID != "" {
 				owner = defaultOwnerID
@@ -37,10 +52,207 @@ func OwnerCtx(defaultOwnerID string) func(http.Handler) http.Handler {
 	}
 }
 
+// OrganizationCtx makes organization from header the owner of request,
+// acting user must be its member, requests without header keep their owner
+func OrganizationCtx(orgs *service.Organization) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			log := GetLogger(r)
+			code := r.Header.Get(http.CanonicalHeaderKey(XTogglyOrgID))
+			if code == "" || models.SDKKeyFromContext(r) != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+			user := models.UserFromContext(r)
+			org, err := orgs.Get(user, code)
+			if err != nil {
+				log.Errorf("Organization.Service.Get: %s", err.Error())
+				models.ErrorResponse(w, r, err)
+				return
+			}
+			ctx := r.Context()
+			ctx = context.WithValue(ctx, models.CtxValueOwner, org.ID.Hex())
+			ctx = context.WithValue(ctx, models.CtxValueUser, user)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		}
+		return http.HandlerFunc(fn)
+	}
+}
+
+// RequestIDCtx keeps request id in context for audit records,
+// it is taken from request header or from the one assigned to response
+func RequestIDCtx(next http.Handler) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(models.XRequestID)
+		if id == "" {
+			id = w.Header().Get(models.XRequestID)
+		}
+		if id == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		ctx := r.Context()
+		ctx = context.WithValue(ctx, models.CtxValueRequestID, id)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	}
+	return http.HandlerFunc(fn)
+}
+
+// SDKKeyCtx resolves owner, project and environment from SDK key,
+// browsers can't set headers for streams so key is accepted in sdk_key query parameter there
+func SDKKeyCtx(keys *service.SDKKey) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			log := GetLogger(r)
+			value := r.Header.Get(http.CanonicalHeaderKey(XTogglySDKKey))
+			if value == "" && isStreaming(r) {
+				value = r.URL.Query().Get("sdk_key")
+			}
+			if value == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			key := keys.Resolve(value)
+			if key == nil {
+				log.Error("SDK key is invalid or expired")
+				models.UnauthorizedResponse(w, r)
+				return
+			}
+			ctx := r.Context()
+			ctx = context.WithValue(ctx, models.CtxValueSDKKey, key)
+			ctx = context.WithValue(ctx, models.CtxValueOwner, key.OwnerID)
+			ctx = context.WithValue(ctx, models.CtxValueProject, key.Project)
+			ctx = context.WithValue(ctx, models.CtxValueEnvID, key.Environment)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		}
+		return http.HandlerFunc(fn)
+	}
+}
+
+// CSRFHeader protects cookie sessions from cross-site requests, browsers don't add custom headers
+// to cross-site requests without CORS preflight, so requests changing data must carry one
+func CSRFHeader(next http.Handler) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet || r.Method == http.MethodHead || r.Method == http.MethodOptions ||
+			models.SDKKeyFromContext(r) != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if r.Header.Get(http.CanonicalHeaderKey(XRequestedWith)) == "" {
+			GetLogger(r).Error("Header X-Requested-With missed")
+			models.ForbiddenResponse(w, r, "Header X-Requested-With is required")
+			return
+		}
+		next.ServeHTTP(w, r)
+	}
+	return http.HandlerFunc(fn)
+}
+
+// DenySDKKey protects management API from SDK keys
+func DenySDKKey(next http.Handler) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		if models.SDKKeyFromContext(r) != nil {
+			GetLogger(r).Error("SDK key is used for management API")
+			models.ForbiddenResponse(w, r, "SDK key can't be used for management API")
+			return
+		}
+		next.ServeHTTP(w, r)
+	}
+	return http.HandlerFunc(fn)
+}
+
+// ProjectAccess checks acting user role in project from route,
+// reading requests need read permission only, SDK keys are checked against route by endpoints
+func ProjectAccess(access *service.Access, perm string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			log := GetLogger(r)
+			if models.SDKKeyFromContext(r) != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+			required := perm
+			if r.Method == http.MethodGet || r.Method == http.MethodHead || r.Method == http.MethodOptions {
+				required = models.PermissionRead
+			}
+			err := access.Check(
+				models.OwnerFromContext(r),
+				models.UserFromContext(r),
+				chi.URLParam(r, "ProjectCode"),
+				chi.URLParam(r, "EnvCode"),
+				required,
+			)
+			if err != nil {
+				log.Errorf("Access denied: %s", err.Error())
+				models.ErrorResponse(w, r, err)
+				return
+			}
+			next.ServeHTTP(w, r)
+		}
+		return http.HandlerFunc(fn)
+	}
+}
+
+// EvaluationAccess checks that acting user can read project and environment from context,
+// SDK keys define them by themselves
+func EvaluationAccess(access *service.Access) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			log := GetLogger(r)
+			if models.SDKKeyFromContext(r) != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+			err := access.Check(
+				models.OwnerFromContext(r),
+				models.UserFromContext(r),
+				models.ProjectFromContext(r),
+				models.EnvironmentFromContext(r),
+				models.PermissionRead,
+			)
+			if err != nil {
+				log.Errorf("Access denied: %s", err.Error())
+				models.ErrorResponse(w, r, err)
+				return
+			}
+			next.ServeHTTP(w, r)
+		}
+		return http.HandlerFunc(fn)
+	}
+}
+
+// UserCtx adds acting user to context, owner acts by itself when header is missed
+// unless user is required, SDK keys act without user
+func UserCtx(required bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			log := GetLogger(r)
+			user := r.Header.Get(http.CanonicalHeaderKey(XTogglyUserID))
+			if user == "" {
+				if required && models.SDKKeyFromContext(r) == nil {
+					log.Error("Header X-Toggly-User-Id missed")
+					models.UnauthorizedResponse(w, r)
+					return
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+			ctx := r.Context()
+			ctx = context.WithValue(ctx, models.CtxValueUser, user)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		}
+		return http.HandlerFunc(fn)
+	}
+}
+
 // EnvironmentCtx adds auth data to context
 func EnvironmentCtx(next http.Handler) http.Handler {
 	fn := func(w http.ResponseWriter, r *http.Request) {
 		log := GetLogger(r)
+		if models.SDKKeyFromContext(r) != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
 		env := r.Header.Get(http.CanonicalHeaderKey(XTogglyEnvID))
 		if env == "" {
 			log.Error("Environemnt context is missed")
@@ -54,8 +266,67 @@ func EnvironmentCtx(next http.Handler) http.Handler {
 	return http.HandlerFunc(fn)
 }
 
+// ProjectCtx adds project code to context
+func ProjectCtx(next http.Handler) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		log := GetLogger(r)
+		if models.SDKKeyFromContext(r) != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		project := r.Header.Get(http.CanonicalHeaderKey(XTogglyProjectID))
+		if project == "" {
+			log.Error("Project context is missed")
+			models.ForbiddenResponse(w, r, "Unable to determine project")
+			return
+		}
+		ctx := r.Context()
+		ctx = context.WithValue(ctx, models.CtxValueProject, project)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	}
+	return http.HandlerFunc(fn)
+}
+
+func isStreaming(r *http.Request) bool {
+	return strings.Contains(r.Header.Get("Accept"), "text/event-stream") ||
+		strings.EqualFold(r.Header.Get("Upgrade"), "websocket")
+}
+
 // GetLogger gets logger instance from context
 func GetLogger(r *http.Request) *utils.StructuredLogger {
 	log := r.Context().Value(models.ContextLoggerKey).(*logging.Logger)
 	return &utils.StructuredLogger{Logger: log, R: r}
 }
+
+// ValidateRequest checks request body against operation schema from specification,
+// body is kept for handlers
+func ValidateRequest(spec *OpenAPI) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			log := GetLogger(r)
+			path := r.URL.Path
+			if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePath != "" {
+				path = rctx.RoutePath
+			}
+			op := spec.Operation(r.Method, path)
+			if op == nil || op.RequestBody == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+			body, err := ioutil.ReadAll(r.Body)
+			if err != nil {
+				log.Error("Can't read request body")
+				models.ErrorResponseWithStatus(w, r, err, http.StatusInternalServerError)
+				return
+			}
+			if err := spec.ValidateBody(op, body); err != nil {
+				log.Errorf("Request validation: %s", err.Error())
+				models.ErrorResponse(w, r, err)
+				return
+			}
+			r.Body = ioutil.NopCloser(bytes.NewReader(body))
+			next.ServeHTTP(w, r)
+		}
+		return http.HandlerFunc(fn)
+	}
+}
@@ -0,0 +1,39 @@
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"bitbucket.org/toggly/toggly-server/models"
+	"github.com/go-chi/chi"
+)
+
+func TestUserIsRequiredInMultiUserMode(t *testing.T) {
+	toggly := newTestApp(t, &models.Config{MultiUserMode: true})
+	router := chi.ServerBaseContext(toggly.Ctx, toggly.Router("/"))
+	do := func(method string, path string, body string, user string) int {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set(XTogglyOwnerID, "acme")
+		if user != "" {
+			req.Header.Set(XTogglyUserID, user)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	if code := do(http.MethodPost, "/v1/project", `{"code":"p1","name":"P1"}`, ""); code != http.StatusUnauthorized {
+		t.Errorf("project is created without user: %d", code)
+	}
+	if code := do(http.MethodPost, "/v1/project", `{"code":"p1","name":"P1"}`, "acme"); code != http.StatusOK {
+		t.Fatalf("project is not created by owner: %d", code)
+	}
+	if code := do(http.MethodGet, "/v1/project/p1", ``, ""); code != http.StatusUnauthorized {
+		t.Errorf("project is read without user: %d", code)
+	}
+	if code := do(http.MethodGet, "/v1/project/p1", ``, "stranger"); code != http.StatusNotFound {
+		t.Errorf("project is read by stranger: %d", code)
+	}
+}
@@ -0,0 +1,275 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+
+	"bitbucket.org/toggly/toggly-server/models"
+	"bitbucket.org/toggly/toggly-server/service"
+	"github.com/go-chi/chi"
+	"github.com/op/go-logging"
+)
+
+// ObjectEndpoints API struct
+type ObjectEndpoints struct {
+	Ctx     context.Context
+	Config  *models.Config
+	Logger  *logging.Logger
+	Service *service.Object
+	Access  *service.Access
+	Changes *service.Change
+	Audit   *service.Audit
+}
+
+// Routes returns api endpoints
+func (a *ObjectEndpoints) Routes() chi.Router {
+	router := chi.NewRouter()
+	router.Group(func(group chi.Router) {
+		group.Use(ProjectAccess(a.Access, models.PermissionWrite))
+		group.Get("/", a.list)
+		group.Post("/", a.create)
+		group.Put("/{InstanceID}", a.update)
+		group.Get("/{InstanceID}", a.get)
+		group.Delete("/{InstanceID}", a.delete)
+		group.Get("/{InstanceID}/env/{EnvCode}/values", a.values)
+		group.Put("/{InstanceID}/env/{EnvCode}/param/{ParamCode}", a.setOverride)
+		group.Delete("/{InstanceID}/env/{EnvCode}/param/{ParamCode}", a.removeOverride)
+	})
+	return router
+}
+
+func (a *ObjectEndpoints) list(w http.ResponseWriter, r *http.Request) {
+	log := GetLogger(r)
+	owner := models.OwnerFromContext(r)
+	project := chi.URLParam(r, "ProjectCode")
+
+	recs, err := a.Service.List(owner, project)
+	if err != nil {
+		log.Errorf("Object.Service.List: %s", err.Error())
+		models.ErrorResponse(w, r, err)
+		return
+	}
+
+	log.Debugf("Object.list: %d items found", len(recs))
+	models.JSONResponse(w, r, recs)
+}
+
+func (a *ObjectEndpoints) create(w http.ResponseWriter, r *http.Request) {
+	log := GetLogger(r)
+	owner := models.OwnerFromContext(r)
+	project := chi.URLParam(r, "ProjectCode")
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		log.Error("Can't read request body")
+		models.ErrorResponseWithStatus(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	var data models.Object
+	if err := json.Unmarshal(body, &data); err != nil {
+		log.Error("Can't parse request body")
+		models.ErrorResponseWithStatus(w, r, err, http.StatusBadRequest)
+		return
+	}
+
+	resp, err := a.Service.Create(owner, project, data)
+	if err != nil {
+		log.Errorf("Object.Service.Create: %s", err.Error())
+		models.ErrorResponse(w, r, err)
+		return
+	}
+
+	log.Debugf("Object: %+v", resp)
+
+	models.JSONResponse(w, r, resp)
+}
+
+func (a *ObjectEndpoints) update(w http.ResponseWriter, r *http.Request) {
+	log := GetLogger(r)
+	owner := models.OwnerFromContext(r)
+	project := chi.URLParam(r, "ProjectCode")
+	instanceID := chi.URLParam(r, "InstanceID")
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		log.Error("Can't read request body")
+		models.ErrorResponseWithStatus(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	var data models.Object
+	if err := json.Unmarshal(body, &data); err != nil {
+		log.Error("Can't parse request body")
+		models.ErrorResponseWithStatus(w, r, err, http.StatusBadRequest)
+		return
+	}
+
+	// instance id is taken from URL and can't be changed
+	data.InstanceID = instanceID
+
+	resp, err := a.Service.Update(owner, project, data)
+	if err != nil {
+		log.Errorf("Object.Service.Update: %s", err.Error())
+		models.ErrorResponse(w, r, err)
+		return
+	}
+
+	log.Debugf("Object: %+v", resp)
+
+	models.JSONResponse(w, r, resp)
+}
+
+func (a *ObjectEndpoints) get(w http.ResponseWriter, r *http.Request) {
+	log := GetLogger(r)
+	owner := models.OwnerFromContext(r)
+	project := chi.URLParam(r, "ProjectCode")
+	instanceID := chi.URLParam(r, "InstanceID")
+
+	resp, err := a.Service.Get(owner, project, instanceID)
+	if err != nil {
+		log.Errorf("Object.Service.Get: %s", err.Error())
+		models.ErrorResponse(w, r, err)
+		return
+	}
+
+	log.Debugf("Object: %+v", resp)
+
+	models.JSONResponse(w, r, resp)
+}
+
+func (a *ObjectEndpoints) delete(w http.ResponseWriter, r *http.Request) {
+	log := GetLogger(r)
+	owner := models.OwnerFromContext(r)
+	project := chi.URLParam(r, "ProjectCode")
+	instanceID := chi.URLParam(r, "InstanceID")
+
+	if err := a.Service.Delete(owner, project, instanceID); err != nil {
+		log.Errorf("Object.Service.Delete: %s", err.Error())
+		models.ErrorResponse(w, r, err)
+		return
+	}
+
+	log.Debugf("Object [%s] deleted", instanceID)
+
+	models.NoContentResponse(w, r)
+}
+
+func (a *ObjectEndpoints) values(w http.ResponseWriter, r *http.Request) {
+	log := GetLogger(r)
+	owner := models.OwnerFromContext(r)
+	project := chi.URLParam(r, "ProjectCode")
+	instanceID := chi.URLParam(r, "InstanceID")
+	env := chi.URLParam(r, "EnvCode")
+
+	resp, err := a.Service.Values(owner, project, instanceID, env)
+	if err != nil {
+		log.Errorf("Object.Service.Values: %s", err.Error())
+		models.ErrorResponse(w, r, err)
+		return
+	}
+
+	log.Debugf("Object.values: %d items found", len(resp))
+	models.JSONResponse(w, r, resp)
+}
+
+func (a *ObjectEndpoints) setOverride(w http.ResponseWriter, r *http.Request) {
+	log := GetLogger(r)
+	owner := models.OwnerFromContext(r)
+	project := chi.URLParam(r, "ProjectCode")
+	instanceID := chi.URLParam(r, "InstanceID")
+	env := chi.URLParam(r, "EnvCode")
+	param := chi.URLParam(r, "ParamCode")
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		log.Error("Can't read request body")
+		models.ErrorResponseWithStatus(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	var data models.Override
+	if err := json.Unmarshal(body, &data); err != nil {
+		log.Error("Can't parse request body")
+		models.ErrorResponseWithStatus(w, r, err, http.StatusBadRequest)
+		return
+	}
+
+	if a.Changes.IsRequired(owner, project, env) {
+		proposeChange(w, r, a.Changes, project, env, models.ChangeRequest{
+			Entity:   models.ChangeEntityOverride,
+			Action:   models.ChangeActionUpdate,
+			Code:     param,
+			Instance: instanceID,
+			Value:    data.Value,
+		})
+		return
+	}
+
+	before := objectOverride(a.Service, owner, project, instanceID, env, param)
+
+	resp, err := a.Service.SetOverride(owner, project, instanceID, env, param, data.Value)
+	if err != nil {
+		log.Errorf("Object.Service.SetOverride: %s", err.Error())
+		models.ErrorResponse(w, r, err)
+		return
+	}
+
+	a.Audit.Record(overrideRecord(r, models.ChangeActionUpdate, project, env, instanceID, param, before), before, resp.Override(env, param))
+
+	log.Debugf("Object: %+v", resp)
+
+	models.JSONResponse(w, r, resp)
+}
+
+func (a *ObjectEndpoints) removeOverride(w http.ResponseWriter, r *http.Request) {
+	log := GetLogger(r)
+	owner := models.OwnerFromContext(r)
+	project := chi.URLParam(r, "ProjectCode")
+	instanceID := chi.URLParam(r, "InstanceID")
+	env := chi.URLParam(r, "EnvCode")
+	param := chi.URLParam(r, "ParamCode")
+
+	if a.Changes.IsRequired(owner, project, env) {
+		proposeChange(w, r, a.Changes, project, env, models.ChangeRequest{
+			Entity:   models.ChangeEntityOverride,
+			Action:   models.ChangeActionDelete,
+			Code:     param,
+			Instance: instanceID,
+		})
+		return
+	}
+
+	before := objectOverride(a.Service, owner, project, instanceID, env, param)
+
+	resp, err := a.Service.RemoveOverride(owner, project, instanceID, env, param)
+	if err != nil {
+		log.Errorf("Object.Service.RemoveOverride: %s", err.Error())
+		models.ErrorResponse(w, r, err)
+		return
+	}
+
+	a.Audit.Record(overrideRecord(r, models.ChangeActionDelete, project, env, instanceID, param, before), before, nil)
+
+	log.Debugf("Object: %+v", resp)
+
+	models.JSONResponse(w, r, resp)
+}
+
+// objectOverride returns current object override of environment parameter, nil when there is none
+func objectOverride(svc *service.Object, owner string, project string, instanceID string, env string, param string) *models.Override {
+	item, err := svc.Get(owner, project, instanceID)
+	if err != nil {
+		return nil
+	}
+	return item.Override(env, param)
+}
+
+// overrideRecord returns audit record of override change, setting missing override creates it
+func overrideRecord(r *http.Request, action string, project string, env string, instanceID string, param string, before *models.Override) *models.AuditRecord {
+	if action == models.ChangeActionUpdate && before == nil {
+		action = models.AuditActionCreate
+	}
+	rec := auditRecord(r, models.AuditEntityOverride, action, project, env, param)
+	rec.Instance = instanceID
+	return rec
+}
@@ -0,0 +1,783 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"bitbucket.org/toggly/toggly-server/models"
+)
+
+// OpenAPI document, only parts of OpenAPI 3 used by Toggly API are described
+type OpenAPI struct {
+	OpenAPI    string                                  `json:"openapi"`
+	Info       *OpenAPIInfo                            `json:"info"`
+	Servers    []*OpenAPIServer                        `json:"servers"`
+	Security   []map[string][]string                   `json:"security"`
+	Paths      map[string]map[string]*OpenAPIOperation `json:"paths"`
+	Components *OpenAPIComponents                      `json:"components"`
+}
+
+// OpenAPIInfo struct
+type OpenAPIInfo struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+// OpenAPIServer struct
+type OpenAPIServer struct {
+	URL string `json:"url"`
+}
+
+// OpenAPIOperation struct, empty security requirement makes operation public
+type OpenAPIOperation struct {
+	Tags        []string                    `json:"tags"`
+	Summary     string                      `json:"summary"`
+	OperationID string                      `json:"operationId"`
+	Parameters  []*OpenAPIParameter         `json:"parameters,omitempty"`
+	RequestBody *OpenAPIRequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*OpenAPIResponse `json:"responses"`
+	Security    []map[string][]string       `json:"security,omitempty"`
+}
+
+// OpenAPIParameter struct, describes path and query parameters
+type OpenAPIParameter struct {
+	Name     string         `json:"name"`
+	In       string         `json:"in"`
+	Required bool           `json:"required,omitempty"`
+	Schema   *OpenAPISchema `json:"schema"`
+}
+
+// OpenAPIRequestBody struct
+type OpenAPIRequestBody struct {
+	Required bool                         `json:"required"`
+	Content  map[string]*OpenAPIMediaType `json:"content"`
+}
+
+// OpenAPIResponse struct
+type OpenAPIResponse struct {
+	Description string                       `json:"description"`
+	Content     map[string]*OpenAPIMediaType `json:"content,omitempty"`
+}
+
+// OpenAPIMediaType struct
+type OpenAPIMediaType struct {
+	Schema *OpenAPISchema `json:"schema"`
+}
+
+// OpenAPIComponents struct
+type OpenAPIComponents struct {
+	Schemas         map[string]*OpenAPISchema         `json:"schemas"`
+	SecuritySchemes map[string]*OpenAPISecurityScheme `json:"securitySchemes"`
+}
+
+// OpenAPISecurityScheme struct
+type OpenAPISecurityScheme struct {
+	Type         string `json:"type"`
+	Name         string `json:"name,omitempty"`
+	In           string `json:"in,omitempty"`
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+	Description  string `json:"description,omitempty"`
+}
+
+// OpenAPISchema struct, schema without type accepts any value
+type OpenAPISchema struct {
+	Ref                  string                    `json:"$ref,omitempty"`
+	AllOf                []*OpenAPISchema          `json:"allOf,omitempty"`
+	Type                 string                    `json:"type,omitempty"`
+	Format               string                    `json:"format,omitempty"`
+	Enum                 []interface{}             `json:"enum,omitempty"`
+	Properties           map[string]*OpenAPISchema `json:"properties,omitempty"`
+	AdditionalProperties *OpenAPISchema            `json:"additionalProperties,omitempty"`
+	Required             []string                  `json:"required,omitempty"`
+	Items                *OpenAPISchema            `json:"items,omitempty"`
+	ReadOnly             bool                      `json:"readOnly,omitempty"`
+}
+
+const schemaRefPrefix = "#/components/schemas/"
+
+func typed(kind string) *OpenAPISchema {
+	return &OpenAPISchema{Type: kind}
+}
+
+func dateTime() *OpenAPISchema {
+	return &OpenAPISchema{Type: "string", Format: "date-time", ReadOnly: true}
+}
+
+func readOnly(kind string) *OpenAPISchema {
+	return &OpenAPISchema{Type: kind, ReadOnly: true}
+}
+
+func anyValue() *OpenAPISchema {
+	return &OpenAPISchema{}
+}
+
+func ref(name string) *OpenAPISchema {
+	return &OpenAPISchema{Ref: schemaRefPrefix + name}
+}
+
+func arrayOf(items *OpenAPISchema) *OpenAPISchema {
+	return &OpenAPISchema{Type: "array", Items: items}
+}
+
+func mapOf(values *OpenAPISchema) *OpenAPISchema {
+	return &OpenAPISchema{Type: "object", AdditionalProperties: values}
+}
+
+func enumOf(values ...string) *OpenAPISchema {
+	enum := make([]interface{}, len(values))
+	for i, v := range values {
+		enum[i] = v
+	}
+	return &OpenAPISchema{Type: "string", Enum: enum}
+}
+
+func object(properties map[string]*OpenAPISchema) *OpenAPISchema {
+	return &OpenAPISchema{Type: "object", Properties: properties}
+}
+
+// requires marks fields of referenced schema as required in request
+func requires(schema *OpenAPISchema, fields ...string) *OpenAPISchema {
+	return &OpenAPISchema{AllOf: []*OpenAPISchema{schema, {Type: "object", Required: fields}}}
+}
+
+// route describes single API operation, paths are relative to /v1
+type route struct {
+	method   string
+	path     string
+	tag      string
+	summary  string
+	body     *OpenAPISchema
+	optional bool
+	status   int
+	resp     *OpenAPISchema
+	content  string
+	query    []string
+	proposes bool
+	public   bool
+}
+
+var authRoutes = []route{
+	{method: "POST", path: "/auth/register", tag: "auth", summary: "Register user account and start session", body: requires(ref("Credentials"), "email", "password"), resp: ref("User"), public: true},
+	{method: "POST", path: "/auth/login", tag: "auth", summary: "Start session", body: requires(ref("Credentials"), "email", "password"), resp: ref("User"), public: true},
+	{method: "POST", path: "/auth/logout", tag: "auth", summary: "Finish session", public: true},
+	{method: "GET", path: "/auth/me", tag: "auth", summary: "Get session user", resp: ref("User")},
+}
+
+var apiRoutes = []route{
+	{method: "GET", path: "/openapi.json", tag: "openapi", summary: "Get OpenAPI specification", resp: object(nil), public: true},
+
+	{method: "GET", path: "/org", tag: "organization", summary: "List organizations of user", resp: arrayOf(ref("Organization"))},
+	{method: "POST", path: "/org", tag: "organization", summary: "Create organization", body: requires(ref("Organization"), "code", "name"), resp: ref("Organization")},
+	{method: "GET", path: "/org/{OrgCode}", tag: "organization", summary: "Get organization", resp: ref("Organization")},
+	{method: "PUT", path: "/org/{OrgCode}", tag: "organization", summary: "Update organization", body: requires(ref("Organization"), "name"), resp: ref("Organization")},
+	{method: "DELETE", path: "/org/{OrgCode}", tag: "organization", summary: "Delete organization"},
+	{method: "PUT", path: "/org/{OrgCode}/member/{UserID}", tag: "organization", summary: "Set organization member role", body: requires(ref("OrganizationMember"), "role"), resp: ref("Organization")},
+	{method: "DELETE", path: "/org/{OrgCode}/member/{UserID}", tag: "organization", summary: "Remove organization member"},
+	{method: "PUT", path: "/org/{OrgCode}/team/{TeamCode}", tag: "organization", summary: "Create or update team", body: ref("Team"), resp: ref("Organization")},
+	{method: "DELETE", path: "/org/{OrgCode}/team/{TeamCode}", tag: "organization", summary: "Delete team"},
+	{method: "GET", path: "/org/{OrgCode}/invitation", tag: "organization", summary: "List organization invitations", resp: arrayOf(ref("Invitation"))},
+	{method: "POST", path: "/org/{OrgCode}/invitation", tag: "organization", summary: "Invite to organization", body: requires(ref("InvitationRequest"), "email", "role"), resp: ref("Invitation")},
+	{method: "DELETE", path: "/org/{OrgCode}/invitation/{InvitationID}", tag: "organization", summary: "Revoke organization invitation"},
+
+	{method: "GET", path: "/invitation", tag: "invitation", summary: "List pending invitations of user", resp: arrayOf(ref("Invitation"))},
+	{method: "POST", path: "/invitation/accept", tag: "invitation", summary: "Accept invitation by token", body: requires(ref("InvitationToken"), "token"), resp: ref("Invitation")},
+	{method: "POST", path: "/invitation/decline", tag: "invitation", summary: "Decline invitation by token", body: requires(ref("InvitationToken"), "token"), resp: ref("Invitation")},
+	{method: "POST", path: "/invitation/{InvitationID}/accept", tag: "invitation", summary: "Accept invitation", resp: ref("Invitation")},
+	{method: "POST", path: "/invitation/{InvitationID}/decline", tag: "invitation", summary: "Decline invitation", resp: ref("Invitation")},
+
+	{method: "GET", path: "/project", tag: "project", summary: "List projects", resp: arrayOf(ref("Project"))},
+	{method: "POST", path: "/project", tag: "project", summary: "Create project", body: requires(ref("Project"), "code", "name"), resp: ref("Project")},
+	{method: "GET", path: "/project/{ProjectCode}", tag: "project", summary: "Get project", resp: ref("Project")},
+	{method: "PUT", path: "/project/{ProjectCode}", tag: "project", summary: "Update project", body: requires(ref("Project"), "name"), resp: ref("Project")},
+	{method: "PATCH", path: "/project/{ProjectCode}", tag: "project", summary: "Update project with JSON merge patch", body: object(nil), resp: ref("Project")},
+	{method: "DELETE", path: "/project/{ProjectCode}", tag: "project", summary: "Delete project"},
+	{method: "POST", path: "/project/{ProjectCode}/archive", tag: "project", summary: "Archive project", resp: ref("Project")},
+	{method: "POST", path: "/project/{ProjectCode}/restore", tag: "project", summary: "Restore archived project", resp: ref("Project")},
+	{method: "GET", path: "/project/{ProjectCode}/member", tag: "project", summary: "List project members", resp: arrayOf(ref("ProjectMember"))},
+	{method: "PUT", path: "/project/{ProjectCode}/member/{UserID}", tag: "project", summary: "Set project member role", body: requires(ref("ProjectMember"), "role"), resp: arrayOf(ref("ProjectMember"))},
+	{method: "DELETE", path: "/project/{ProjectCode}/member/{UserID}", tag: "project", summary: "Remove project member"},
+	{method: "PUT", path: "/project/{ProjectCode}/team/{TeamCode}", tag: "project", summary: "Set project team role", body: requires(ref("ProjectMember"), "role"), resp: arrayOf(ref("ProjectMember"))},
+	{method: "DELETE", path: "/project/{ProjectCode}/team/{TeamCode}", tag: "project", summary: "Remove project team"},
+	{method: "GET", path: "/project/{ProjectCode}/invitation", tag: "project", summary: "List project invitations", resp: arrayOf(ref("Invitation"))},
+	{method: "POST", path: "/project/{ProjectCode}/invitation", tag: "project", summary: "Invite to project", body: requires(ref("InvitationRequest"), "email", "role"), resp: ref("Invitation")},
+	{method: "DELETE", path: "/project/{ProjectCode}/invitation/{InvitationID}", tag: "project", summary: "Revoke project invitation"},
+
+	{method: "GET", path: "/project/{ProjectCode}/env", tag: "environment", summary: "List environments", resp: arrayOf(ref("Environment"))},
+	{method: "POST", path: "/project/{ProjectCode}/env", tag: "environment", summary: "Create environment", body: requires(ref("Environment"), "code"), resp: ref("Environment")},
+	{method: "GET", path: "/project/{ProjectCode}/env/{EnvCode}", tag: "environment", summary: "Get environment", resp: ref("Environment")},
+	{method: "PUT", path: "/project/{ProjectCode}/env/{EnvCode}", tag: "environment", summary: "Update environment", body: ref("Environment"), resp: ref("Environment")},
+	{method: "DELETE", path: "/project/{ProjectCode}/env/{EnvCode}", tag: "environment", summary: "Delete environment"},
+
+	{method: "GET", path: "/project/{ProjectCode}/env/{EnvCode}/param", tag: "parameter", summary: "List parameters", resp: arrayOf(ref("Parameter"))},
+	{method: "POST", path: "/project/{ProjectCode}/env/{EnvCode}/param", tag: "parameter", summary: "Create parameter", body: requires(ref("Parameter"), "code", "type"), resp: ref("Parameter"), proposes: true},
+	{method: "GET", path: "/project/{ProjectCode}/env/{EnvCode}/param/{ParamCode}", tag: "parameter", summary: "Get parameter", resp: ref("Parameter")},
+	{method: "PUT", path: "/project/{ProjectCode}/env/{EnvCode}/param/{ParamCode}", tag: "parameter", summary: "Update parameter", body: ref("Parameter"), resp: ref("Parameter"), proposes: true},
+	{method: "DELETE", path: "/project/{ProjectCode}/env/{EnvCode}/param/{ParamCode}", tag: "parameter", summary: "Delete parameter", proposes: true},
+	{method: "GET", path: "/project/{ProjectCode}/env/{EnvCode}/param/{ParamCode}/version", tag: "parameter", summary: "List parameter versions", resp: arrayOf(ref("ParameterVersion"))},
+	{method: "GET", path: "/project/{ProjectCode}/env/{EnvCode}/param/{ParamCode}/version/{Version}", tag: "parameter", summary: "Get parameter version", resp: ref("ParameterVersion")},
+	{method: "POST", path: "/project/{ProjectCode}/env/{EnvCode}/param/{ParamCode}/version/{Version}/rollback", tag: "parameter", summary: "Roll parameter back to version", resp: ref("Parameter"), proposes: true},
+	{method: "GET", path: "/project/{ProjectCode}/env/{EnvCode}/param/{ParamCode}/diff", tag: "parameter", summary: "Compare parameter versions", resp: ref("ParameterVersionDiff"), query: []string{"from", "to"}},
+
+	{method: "GET", path: "/project/{ProjectCode}/package", tag: "package", summary: "List packages", resp: arrayOf(ref("Package"))},
+	{method: "POST", path: "/project/{ProjectCode}/package", tag: "package", summary: "Create package", body: requires(ref("Package"), "code", "name"), resp: ref("Package")},
+	{method: "GET", path: "/project/{ProjectCode}/package/{PackageCode}", tag: "package", summary: "Get package", resp: ref("Package")},
+	{method: "PUT", path: "/project/{ProjectCode}/package/{PackageCode}", tag: "package", summary: "Update package", body: requires(ref("Package"), "name"), resp: ref("Package")},
+	{method: "DELETE", path: "/project/{ProjectCode}/package/{PackageCode}", tag: "package", summary: "Delete package"},
+	{method: "GET", path: "/project/{ProjectCode}/package/{PackageCode}/env/{EnvCode}/param", tag: "package", summary: "List package parameters", resp: arrayOf(ref("Parameter"))},
+	{method: "GET", path: "/project/{ProjectCode}/package/{PackageCode}/env/{EnvCode}/values", tag: "package", summary: "Get package values", resp: ref("Values"), query: []string{"instance"}},
+
+	{method: "GET", path: "/project/{ProjectCode}/object", tag: "object", summary: "List objects", resp: arrayOf(ref("Object"))},
+	{method: "POST", path: "/project/{ProjectCode}/object", tag: "object", summary: "Create object", body: requires(ref("Object"), "instanceId"), resp: ref("Object")},
+	{method: "GET", path: "/project/{ProjectCode}/object/{InstanceID}", tag: "object", summary: "Get object", resp: ref("Object")},
+	{method: "PUT", path: "/project/{ProjectCode}/object/{InstanceID}", tag: "object", summary: "Update object", body: ref("Object"), resp: ref("Object")},
+	{method: "DELETE", path: "/project/{ProjectCode}/object/{InstanceID}", tag: "object", summary: "Delete object"},
+	{method: "GET", path: "/project/{ProjectCode}/object/{InstanceID}/env/{EnvCode}/values", tag: "object", summary: "Get object values", resp: ref("Values")},
+	{method: "PUT", path: "/project/{ProjectCode}/object/{InstanceID}/env/{EnvCode}/param/{ParamCode}", tag: "object", summary: "Override parameter value for object", body: ref("Override"), resp: ref("Object"), proposes: true},
+	{method: "DELETE", path: "/project/{ProjectCode}/object/{InstanceID}/env/{EnvCode}/param/{ParamCode}", tag: "object", summary: "Remove parameter override", resp: ref("Object"), proposes: true},
+
+	{method: "GET", path: "/audit", tag: "audit", summary: "List audit records", resp: arrayOf(ref("AuditRecord")), query: []string{"entity", "action", "project", "environment", "code", "instance", "requestId", "user", "from", "to", "limit"}},
+
+	{method: "GET", path: "/project/{ProjectCode}/env/{EnvCode}/promote/{TargetCode}", tag: "promotion", summary: "Preview promotion to target environment", resp: ref("Promotion")},
+	{method: "POST", path: "/project/{ProjectCode}/env/{EnvCode}/promote/{TargetCode}", tag: "promotion", summary: "Promote parameters to target environment", body: ref("PromotionRequest"), optional: true, resp: ref("Promotion"), proposes: true},
+
+	{method: "GET", path: "/project/{ProjectCode}/env/{EnvCode}/key", tag: "key", summary: "List SDK keys", resp: arrayOf(ref("SDKKey"))},
+	{method: "POST", path: "/project/{ProjectCode}/env/{EnvCode}/key", tag: "key", summary: "Create SDK key", body: requires(ref("SDKKeyRequest"), "kind"), resp: ref("SDKKey")},
+	{method: "POST", path: "/project/{ProjectCode}/env/{EnvCode}/key/rotate", tag: "key", summary: "Rotate SDK keys", body: requires(ref("SDKKeyRequest"), "kind"), resp: ref("SDKKey")},
+	{method: "DELETE", path: "/project/{ProjectCode}/env/{EnvCode}/key/{KeyID}", tag: "key", summary: "Delete SDK key"},
+
+	{method: "GET", path: "/project/{ProjectCode}/change", tag: "change", summary: "List change requests", resp: arrayOf(ref("ChangeRequest")), query: []string{"env", "status"}},
+	{method: "GET", path: "/project/{ProjectCode}/change/{ChangeID}", tag: "change", summary: "Get change request", resp: ref("ChangeRequest")},
+	{method: "POST", path: "/project/{ProjectCode}/change/{ChangeID}/comment", tag: "change", summary: "Comment change request", body: requires(ref("ChangeComment"), "text"), resp: ref("ChangeRequest")},
+	{method: "POST", path: "/project/{ProjectCode}/change/{ChangeID}/approve", tag: "change", summary: "Approve change request", resp: ref("ChangeRequest")},
+	{method: "POST", path: "/project/{ProjectCode}/change/{ChangeID}/reject", tag: "change", summary: "Reject change request", resp: ref("ChangeRequest")},
+	{method: "POST", path: "/project/{ProjectCode}/change/{ChangeID}/apply", tag: "change", summary: "Apply approved change request", resp: ref("ChangeRequest")},
+
+	{method: "GET", path: "/evaluate", tag: "evaluation", summary: "Evaluate parameters, query keeps instance and context attributes", resp: ref("Values"), query: []string{"instance"}},
+	{method: "POST", path: "/evaluate", tag: "evaluation", summary: "Evaluate parameters", body: ref("EvaluationRequest"), optional: true, resp: ref("Values")},
+	{method: "GET", path: "/evaluate/{PackageCode}", tag: "evaluation", summary: "Evaluate package parameters, query keeps instance and context attributes", resp: ref("Values"), query: []string{"instance"}},
+	{method: "POST", path: "/evaluate/{PackageCode}", tag: "evaluation", summary: "Evaluate package parameters", body: ref("EvaluationRequest"), optional: true, resp: ref("Values")},
+	{method: "GET", path: "/project/{ProjectCode}/env/{EnvCode}/stream", tag: "evaluation", summary: "Stream parameter changes as server-sent events", resp: typed("string"), content: "text/event-stream", query: []string{"sdk_key"}},
+	{method: "GET", path: "/ws", tag: "evaluation", summary: "Stream parameter changes over websocket", status: http.StatusSwitchingProtocols},
+}
+
+// schemas of API entities, request only fields are not read only
+var schemas = map[string]*OpenAPISchema{
+	"Error":  object(map[string]*OpenAPISchema{"error": typed("string")}),
+	"Values": mapOf(anyValue()),
+	"Credentials": object(map[string]*OpenAPISchema{
+		"email":    typed("string"),
+		"name":     typed("string"),
+		"password": typed("string"),
+	}),
+	"User": object(map[string]*OpenAPISchema{
+		"id":       readOnly("string"),
+		"email":    typed("string"),
+		"name":     typed("string"),
+		"reg_date": dateTime(),
+	}),
+	"Organization": object(map[string]*OpenAPISchema{
+		"id":          readOnly("string"),
+		"code":        typed("string"),
+		"name":        typed("string"),
+		"description": typed("string"),
+		"members":     arrayOf(ref("OrganizationMember")),
+		"teams":       arrayOf(ref("Team")),
+		"reg_date":    dateTime(),
+	}),
+	"OrganizationMember": object(map[string]*OpenAPISchema{
+		"user_id": typed("string"),
+		"role":    enumOf(models.OrgRoleOwner, models.OrgRoleAdmin, models.OrgRoleMember),
+	}),
+	"Team": object(map[string]*OpenAPISchema{
+		"code":    typed("string"),
+		"name":    typed("string"),
+		"members": arrayOf(typed("string")),
+	}),
+	"Invitation": object(map[string]*OpenAPISchema{
+		"id":           readOnly("string"),
+		"organization": readOnly("string"),
+		"project":      readOnly("string"),
+		"email":        readOnly("string"),
+		"role":         readOnly("string"),
+		"environments": arrayOf(typed("string")),
+		"token":        readOnly("string"),
+		"status":       enumOf(models.InvitationStatusPending, models.InvitationStatusAccepted, models.InvitationStatusDeclined, models.InvitationStatusRevoked),
+		"invited_by":   readOnly("string"),
+		"accepted_by":  readOnly("string"),
+		"expires_at":   dateTime(),
+		"reg_date":     dateTime(),
+		"upd_date":     dateTime(),
+	}),
+	"InvitationRequest": object(map[string]*OpenAPISchema{
+		"email":        typed("string"),
+		"role":         typed("string"),
+		"environments": arrayOf(typed("string")),
+		"ttl":          typed("string"),
+	}),
+	"InvitationToken": object(map[string]*OpenAPISchema{"token": typed("string")}),
+	"Project": object(map[string]*OpenAPISchema{
+		"code":        typed("string"),
+		"name":        typed("string"),
+		"status":      readOnly("string"),
+		"description": typed("string"),
+		"members":     &OpenAPISchema{Type: "array", Items: ref("ProjectMember"), ReadOnly: true},
+		"reg_date":    dateTime(),
+	}),
+	"ProjectMember": object(map[string]*OpenAPISchema{
+		"user_id":      typed("string"),
+		"team":         typed("string"),
+		"role":         enumOf(models.RoleOwner, models.RoleAdmin, models.RoleEditor, models.RoleViewer),
+		"environments": arrayOf(typed("string")),
+	}),
+	"Environment": object(map[string]*OpenAPISchema{
+		"code":        typed("string"),
+		"description": typed("string"),
+		"protected":   typed("boolean"),
+		"reg_date":    dateTime(),
+	}),
+	"Package": object(map[string]*OpenAPISchema{
+		"code":        typed("string"),
+		"name":        typed("string"),
+		"description": typed("string"),
+		"reg_date":    dateTime(),
+	}),
+	"Parameter": object(map[string]*OpenAPISchema{
+		"code":           typed("string"),
+		"package":        typed("string"),
+		"description":    typed("string"),
+		"type":           enumOf(models.ParameterTypeBool, models.ParameterTypeString, models.ParameterTypeInt),
+		"value":          anyValue(),
+		"allowed_values": arrayOf(anyValue()),
+		"rules":          arrayOf(ref("Rule")),
+		"rollout":        ref("Rollout"),
+		"client":         typed("boolean"),
+		"reg_date":       dateTime(),
+	}),
+	"Rule": object(map[string]*OpenAPISchema{
+		"attribute": typed("string"),
+		"operator": enumOf(
+			models.RuleOperatorEquals,
+			models.RuleOperatorNotEquals,
+			models.RuleOperatorIn,
+			models.RuleOperatorContains,
+			models.RuleOperatorRegex,
+			models.RuleOperatorGreaterThan,
+			models.RuleOperatorLessThan,
+		),
+		"operand": anyValue(),
+		"value":   anyValue(),
+	}),
+	"Rollout": object(map[string]*OpenAPISchema{
+		"attribute":  typed("string"),
+		"salt":       typed("string"),
+		"variations": arrayOf(ref("Variation")),
+	}),
+	"Variation": object(map[string]*OpenAPISchema{
+		"weight": typed("number"),
+		"value":  anyValue(),
+	}),
+	"ParameterVersion": object(map[string]*OpenAPISchema{
+		"code":        typed("string"),
+		"version":     typed("integer"),
+		"action":      typed("string"),
+		"rollback_of": typed("integer"),
+		"parameter":   ref("Parameter"),
+		"time":        dateTime(),
+	}),
+	"ParameterVersionDiff": object(map[string]*OpenAPISchema{
+		"code":    typed("string"),
+		"from":    typed("integer"),
+		"to":      typed("integer"),
+		"changes": mapOf(ref("AuditChange")),
+	}),
+	"AuditChange": object(map[string]*OpenAPISchema{
+		"before": anyValue(),
+		"after":  anyValue(),
+	}),
+	"AuditRecord": object(map[string]*OpenAPISchema{
+		"id":          typed("string"),
+		"owner":       typed("string"),
+		"requestId":   typed("string"),
+		"user":        typed("string"),
+		"entity":      typed("string"),
+		"action":      typed("string"),
+		"project":     typed("string"),
+		"environment": typed("string"),
+		"code":        typed("string"),
+		"instance":    typed("string"),
+		"changes":     mapOf(ref("AuditChange")),
+		"time":        dateTime(),
+	}),
+	"Object": object(map[string]*OpenAPISchema{
+		"instanceId": typed("string"),
+		"name":       typed("string"),
+		"props":      mapOf(anyValue()),
+		"overrides":  &OpenAPISchema{Type: "array", Items: ref("Override"), ReadOnly: true},
+		"reg_date":   dateTime(),
+	}),
+	"Override": object(map[string]*OpenAPISchema{
+		"environment": readOnly("string"),
+		"parameter":   readOnly("string"),
+		"value":       anyValue(),
+	}),
+	"Promotion": object(map[string]*OpenAPISchema{
+		"source":    typed("string"),
+		"target":    typed("string"),
+		"protected": typed("boolean"),
+		"items":     arrayOf(ref("PromotionItem")),
+	}),
+	"PromotionItem": object(map[string]*OpenAPISchema{
+		"code":    typed("string"),
+		"action":  typed("string"),
+		"changes": mapOf(ref("AuditChange")),
+	}),
+	"PromotionRequest": object(map[string]*OpenAPISchema{
+		"parameters": arrayOf(typed("string")),
+		"confirm":    typed("boolean"),
+	}),
+	"SDKKey": object(map[string]*OpenAPISchema{
+		"id":          readOnly("string"),
+		"project":     readOnly("string"),
+		"environment": readOnly("string"),
+		"kind":        enumOf(models.SDKKeyServer, models.SDKKeyClient),
+		"prefix":      readOnly("string"),
+		"key":         readOnly("string"),
+		"expires_at":  dateTime(),
+		"reg_date":    dateTime(),
+	}),
+	"SDKKeyRequest": object(map[string]*OpenAPISchema{
+		"kind":  enumOf(models.SDKKeyServer, models.SDKKeyClient),
+		"grace": typed("string"),
+	}),
+	"ChangeRequest": object(map[string]*OpenAPISchema{
+		"id":          readOnly("string"),
+		"environment": readOnly("string"),
+		"entity":      readOnly("string"),
+		"action":      readOnly("string"),
+		"code":        readOnly("string"),
+		"instance":    readOnly("string"),
+		"value":       anyValue(),
+		"parameter":   ref("Parameter"),
+		"version":     readOnly("integer"),
+		"source":      readOnly("string"),
+		"promotion":   ref("PromotionRequest"),
+		"preview":     ref("Promotion"),
+		"status":      enumOf(models.ChangeStatusPending, models.ChangeStatusApproved, models.ChangeStatusRejected, models.ChangeStatusApplied, models.ChangeStatusFailed),
+		"author":      readOnly("string"),
+		"reviewer":    readOnly("string"),
+		"error":       readOnly("string"),
+		"comments":    arrayOf(ref("ChangeComment")),
+		"reg_date":    dateTime(),
+		"upd_date":    dateTime(),
+	}),
+	"ChangeComment": object(map[string]*OpenAPISchema{
+		"author":   readOnly("string"),
+		"text":     typed("string"),
+		"reg_date": dateTime(),
+	}),
+	"EvaluationRequest": object(map[string]*OpenAPISchema{
+		"instance": typed("string"),
+		"context":  mapOf(anyValue()),
+	}),
+}
+
+var securitySchemes = map[string]*OpenAPISecurityScheme{
+	"owner": {
+		Type:        "apiKey",
+		In:          "header",
+		Name:        XTogglyOwnerID,
+		Description: "Owner id in header mode, acting user is taken from " + XTogglyUserID + " header required in multi user mode",
+	},
+	"sdkKey": {
+		Type:        "apiKey",
+		In:          "header",
+		Name:        XTogglySDKKey,
+		Description: "SDK key, evaluation API only",
+	},
+	"jwt": {
+		Type:         "http",
+		Scheme:       "bearer",
+		BearerFormat: "JWT",
+	},
+	"session": {
+		Type:        "apiKey",
+		In:          "cookie",
+		Name:        "TGLY_SID",
+		Description: "Session cookie, requests changing data must carry " + XRequestedWith + " header",
+	},
+}
+
+// NewOpenAPI builds specification of API v1, account endpoints exist in session mode only
+func NewOpenAPI(sessionMode bool) *OpenAPI {
+	spec := &OpenAPI{
+		OpenAPI: "3.0.2",
+		Info:    &OpenAPIInfo{Title: "Toggly API", Version: "v1"},
+		Servers: []*OpenAPIServer{{URL: "/v1"}},
+		Paths:   make(map[string]map[string]*OpenAPIOperation),
+		Components: &OpenAPIComponents{
+			Schemas:         schemas,
+			SecuritySchemes: securitySchemes,
+		},
+	}
+	// any of auth schemes is enough, which one works depends on auth mode
+	for _, name := range []string{"owner", "sdkKey", "jwt", "session"} {
+		spec.Security = append(spec.Security, map[string][]string{name: {}})
+	}
+	routes := apiRoutes
+	if sessionMode {
+		routes = append(authRoutes, apiRoutes...)
+	}
+	for _, rt := range routes {
+		if spec.Paths[rt.path] == nil {
+			spec.Paths[rt.path] = make(map[string]*OpenAPIOperation)
+		}
+		spec.Paths[rt.path][strings.ToLower(rt.method)] = rt.operation()
+	}
+	return spec
+}
+
+// operation builds operation from route, path parameters are taken from path template
+func (rt route) operation() *OpenAPIOperation {
+	op := &OpenAPIOperation{
+		Tags:        []string{rt.tag},
+		Summary:     rt.summary,
+		OperationID: operationID(rt.method, rt.path),
+		Responses:   make(map[string]*OpenAPIResponse),
+	}
+	for _, segment := range splitPath(rt.path) {
+		if isPathParam(segment) {
+			op.Parameters = append(op.Parameters, &OpenAPIParameter{
+				Name:     strings.Trim(segment, "{}"),
+				In:       "path",
+				Required: true,
+				Schema:   typed("string"),
+			})
+		}
+	}
+	for _, name := range rt.query {
+		op.Parameters = append(op.Parameters, &OpenAPIParameter{Name: name, In: "query", Schema: typed("string")})
+	}
+	if rt.body != nil {
+		op.RequestBody = &OpenAPIRequestBody{
+			Required: !rt.optional,
+			Content:  map[string]*OpenAPIMediaType{"application/json": {Schema: rt.body}},
+		}
+	}
+	status := rt.status
+	if status == 0 {
+		status = http.StatusOK
+		if rt.resp == nil {
+			status = http.StatusNoContent
+		}
+	}
+	resp := &OpenAPIResponse{Description: http.StatusText(status)}
+	if rt.resp != nil {
+		content := rt.content
+		if content == "" {
+			content = "application/json"
+		}
+		resp.Content = map[string]*OpenAPIMediaType{content: {Schema: rt.resp}}
+	}
+	op.Responses[strconv.Itoa(status)] = resp
+	if rt.proposes {
+		op.Responses["202"] = &OpenAPIResponse{
+			Description: "Change request is created for protected environment",
+			Content:     map[string]*OpenAPIMediaType{"application/json": {Schema: ref("ChangeRequest")}},
+		}
+	}
+	op.Responses["default"] = &OpenAPIResponse{
+		Description: "Error",
+		Content:     map[string]*OpenAPIMediaType{"application/json": {Schema: ref("Error")}},
+	}
+	if rt.public {
+		op.Security = []map[string][]string{{}}
+	}
+	return op
+}
+
+// operationID builds unique id like getProjectEnvParam from method and literal path segments
+func operationID(method string, path string) string {
+	id := strings.ToLower(method)
+	for _, segment := range splitPath(path) {
+		if isPathParam(segment) {
+			segment = "by" + strings.Trim(segment, "{}")
+		}
+		segment = strings.Replace(strings.Title(strings.Replace(segment, ".", " ", -1)), " ", "", -1)
+		id += segment
+	}
+	return id
+}
+
+func splitPath(path string) []string {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, "/")
+}
+
+func isPathParam(segment string) bool {
+	return strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}")
+}
+
+// ServeHTTP responds with specification
+func (s *OpenAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	models.JSONResponse(w, r, s)
+}
+
+// Operation finds operation by method and request path relative to /v1,
+// template with more literal segments wins
+func (s *OpenAPI) Operation(method string, path string) *OpenAPIOperation {
+	segments := splitPath(path)
+	var found *OpenAPIOperation
+	best := -1
+	for template, ops := range s.Paths {
+		op, ok := ops[strings.ToLower(method)]
+		if !ok {
+			continue
+		}
+		if literals, ok := matchPath(splitPath(template), segments); ok && literals > best {
+			found, best = op, literals
+		}
+	}
+	return found
+}
+
+// matchPath checks path segments against template and counts matched literal segments
+func matchPath(template []string, segments []string) (int, bool) {
+	if len(template) != len(segments) {
+		return 0, false
+	}
+	literals := 0
+	for i, segment := range template {
+		if isPathParam(segment) {
+			continue
+		}
+		if segment != segments[i] {
+			return 0, false
+		}
+		literals++
+	}
+	return literals, true
+}
+
+// ValidateBody checks request body of operation against its schema
+func (s *OpenAPI) ValidateBody(op *OpenAPIOperation, body []byte) error {
+	if op.RequestBody == nil {
+		return nil
+	}
+	if len(strings.TrimSpace(string(body))) == 0 {
+		if op.RequestBody.Required {
+			return models.ErrBadRequest("Request body is required")
+		}
+		return nil
+	}
+	var data interface{}
+	if err := json.Unmarshal(body, &data); err != nil {
+		return models.ErrBadRequest(fmt.Sprintf("Request body is malformed: %s", err.Error()))
+	}
+	return s.validate(op.RequestBody.Content["application/json"].Schema, data, "")
+}
+
+// validate checks decoded JSON value against schema, nulls and unknown fields are allowed
+func (s *OpenAPI) validate(schema *OpenAPISchema, v interface{}, field string) error {
+	if schema.Ref != "" {
+		return s.validate(s.Components.Schemas[strings.TrimPrefix(schema.Ref, schemaRefPrefix)], v, field)
+	}
+	for _, sub := range schema.AllOf {
+		if err := s.validate(sub, v, field); err != nil {
+			return err
+		}
+	}
+	if v == nil {
+		return nil
+	}
+	switch schema.Type {
+	case "object":
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			return invalidField(field, "an object")
+		}
+		for _, name := range schema.Required {
+			if obj[name] == nil {
+				return models.ErrBadRequest(fmt.Sprintf("Field [%s] is required", fieldPath(field, name)))
+			}
+		}
+		names := make([]string, 0, len(obj))
+		for name := range obj {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			prop := schema.Properties[name]
+			if prop == nil {
+				prop = schema.AdditionalProperties
+			}
+			if prop == nil {
+				continue
+			}
+			if err := s.validate(prop, obj[name], fieldPath(field, name)); err != nil {
+				return err
+			}
+		}
+	case "array":
+		arr, ok := v.([]interface{})
+		if !ok {
+			return invalidField(field, "an array")
+		}
+		if schema.Items != nil {
+			for i, item := range arr {
+				if err := s.validate(schema.Items, item, fmt.Sprintf("%s[%d]", field, i)); err != nil {
+					return err
+				}
+			}
+		}
+	case "string":
+		if _, ok := v.(string); !ok {
+			return invalidField(field, "a string")
+		}
+	case "boolean":
+		if _, ok := v.(bool); !ok {
+			return invalidField(field, "a boolean")
+		}
+	case "number":
+		if _, ok := v.(float64); !ok {
+			return invalidField(field, "a number")
+		}
+	case "integer":
+		if n, ok := v.(float64); !ok || n != math.Trunc(n) {
+			return invalidField(field, "an integer")
+		}
+	}
+	if len(schema.Enum) > 0 {
+		values := make([]string, len(schema.Enum))
+		for i, e := range schema.Enum {
+			if e == v {
+				return nil
+			}
+			values[i] = fmt.Sprint(e)
+		}
+		return invalidField(field, fmt.Sprintf("one of [%s]", strings.Join(values, ", ")))
+	}
+	return nil
+}
+
+func fieldPath(parent string, name string) string {
+	if parent == "" {
+		return name
+	}
+	return parent + "." + name
+}
+
+func invalidField(field string, expected string) error {
+	if field == "" {
+		return models.ErrBadRequest(fmt.Sprintf("Request body must be %s", expected))
+	}
+	return models.ErrBadRequest(fmt.Sprintf("Field [%s] must be %s", field, expected))
+}
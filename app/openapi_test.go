@@ -0,0 +1,120 @@
+package app
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"bitbucket.org/toggly/toggly-server/models"
+	"bitbucket.org/toggly/toggly-server/storage"
+	"github.com/go-chi/chi"
+	"github.com/op/go-logging"
+)
+
+func newTestApp(t *testing.T, config *models.Config) *Toggly {
+	st, err := storage.NewEmbeddedStorage(&models.Storage{Driver: storage.DriverEmbedded, Connection: ":memory:"})
+	if err != nil {
+		t.Fatalf("embedded storage: %s", err.Error())
+	}
+	log := logging.MustGetLogger("test")
+	return &Toggly{
+		Storage: st,
+		Ctx:     context.WithValue(context.Background(), models.ContextLoggerKey, log),
+		Config:  config,
+		Logger:  log,
+	}
+}
+
+// routeTemplates returns method and path template of every v1 route relative to /v1,
+// wildcards chi adds for mounted routers are dropped
+func routeTemplates(t *testing.T, router chi.Routes) map[string]bool {
+	templates := make(map[string]bool)
+	err := chi.Walk(router, func(method string, route string, handler http.Handler, middlewares ...func(http.Handler) http.Handler) error {
+		for strings.Contains(route, "/*/") {
+			route = strings.Replace(route, "/*/", "/", -1)
+		}
+		if !strings.HasPrefix(route, "/v1/") {
+			return nil
+		}
+		templates[method+" "+strings.TrimSuffix(strings.TrimPrefix(route, "/v1"), "/")] = true
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("chi.Walk: %s", err.Error())
+	}
+	return templates
+}
+
+func TestSpecCoversRoutes(t *testing.T) {
+	for _, session := range []bool{false, true} {
+		config := &models.Config{MultiUserMode: true}
+		if session {
+			config = &models.Config{Auth: &models.Auth{Mode: models.AuthModeSession}}
+		}
+		routes := routeTemplates(t, newTestApp(t, config).Router("/"))
+		spec := NewOpenAPI(session)
+
+		for rt := range routes {
+			parts := strings.SplitN(rt, " ", 2)
+			if _, ok := spec.Paths[parts[1]][strings.ToLower(parts[0])]; !ok {
+				t.Errorf("session mode %v: route [%s] has no operation in specification", session, rt)
+			}
+		}
+		for path, ops := range spec.Paths {
+			for method := range ops {
+				if rt := strings.ToUpper(method) + " " + path; !routes[rt] {
+					t.Errorf("session mode %v: operation [%s] has no route", session, rt)
+				}
+			}
+		}
+	}
+}
+
+func TestInvalidBodiesAreRejected(t *testing.T) {
+	toggly := newTestApp(t, &models.Config{MultiUserMode: true})
+	router := chi.ServerBaseContext(toggly.Ctx, toggly.Router("/"))
+	do := func(method string, path string, body string) int {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set(XTogglyOwnerID, "acme")
+		req.Header.Set(XTogglyUserID, "acme")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+	if code := do(http.MethodPost, "/v1/project", `{"code":"p1","name":"P1"}`); code != http.StatusOK {
+		t.Fatalf("project is not created: %d", code)
+	}
+	if code := do(http.MethodPost, "/v1/project/p1/env", `{"code":"dev"}`); code != http.StatusOK {
+		t.Fatalf("environment is not created: %d", code)
+	}
+
+	cases := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+	}{
+		{"malformed project", http.MethodPost, "/v1/project", `{"code":"p2",`},
+		{"empty project", http.MethodPost, "/v1/project", ``},
+		{"project as array", http.MethodPost, "/v1/project", `[]`},
+		{"numeric project code", http.MethodPost, "/v1/project", `{"code":2,"name":"P2"}`},
+		{"missing project name", http.MethodPost, "/v1/project", `{"code":"p2"}`},
+		{"malformed project update", http.MethodPut, "/v1/project/p1", `{"name":`},
+		{"string protected flag", http.MethodPost, "/v1/project/p1/env", `{"code":"qa","protected":"yes"}`},
+		{"unknown parameter type", http.MethodPost, "/v1/project/p1/env/dev/param", `{"code":"f","type":"float","value":1}`},
+		{"rules as object", http.MethodPost, "/v1/project/p1/env/dev/param", `{"code":"f","type":"bool","value":true,"rules":{}}`},
+		{"null rule", http.MethodPost, "/v1/project/p1/env/dev/param", `{"code":"f","type":"bool","value":true,"rules":[null]}`},
+		{"null variation", http.MethodPost, "/v1/project/p1/env/dev/param", `{"code":"f","type":"bool","value":true,"rollout":{"variations":[null]}}`},
+		{"string variation weight", http.MethodPost, "/v1/project/p1/env/dev/param", `{"code":"f","type":"bool","value":true,"rollout":{"variations":[{"weight":"50","value":false}]}}`},
+		{"value of wrong type", http.MethodPost, "/v1/project/p1/env/dev/param", `{"code":"f","type":"int","value":"one"}`},
+		{"malformed object", http.MethodPost, "/v1/project/p1/object", `{"instanceId":`},
+		{"numeric key kind", http.MethodPost, "/v1/project/p1/env/dev/key", `{"kind":1}`},
+	}
+	for _, c := range cases {
+		if code := do(c.method, c.path, c.body); code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", c.name, code)
+		}
+	}
+}
@@ -0,0 +1,298 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"bitbucket.org/toggly/toggly-server/models"
+	"bitbucket.org/toggly/toggly-server/service"
+	"github.com/go-chi/chi"
+	"github.com/op/go-logging"
+)
+
+// OrganizationEndpoints API struct
+type OrganizationEndpoints struct {
+	Ctx         context.Context
+	Config      *models.Config
+	Logger      *logging.Logger
+	Service     *service.Organization
+	Invitations *service.Invitation
+}
+
+// Routes returns api endpoints
+func (a *OrganizationEndpoints) Routes() chi.Router {
+	router := chi.NewRouter()
+	router.Group(func(group chi.Router) {
+		group.Get("/", a.list)
+		group.Post("/", a.create)
+		group.Put("/{OrgCode}", a.update)
+		group.Get("/{OrgCode}", a.get)
+		group.Delete("/{OrgCode}", a.delete)
+		group.Put("/{OrgCode}/member/{UserID}", a.setMember)
+		group.Delete("/{OrgCode}/member/{UserID}", a.removeMember)
+		group.Put("/{OrgCode}/team/{TeamCode}", a.setTeam)
+		group.Delete("/{OrgCode}/team/{TeamCode}", a.removeTeam)
+		group.Get("/{OrgCode}/invitation", a.invitations)
+		group.Post("/{OrgCode}/invitation", a.invite)
+		group.Delete("/{OrgCode}/invitation/{InvitationID}", a.revoke)
+	})
+	return router
+}
+
+func (a *OrganizationEndpoints) list(w http.ResponseWriter, r *http.Request) {
+	log := GetLogger(r)
+	recs := a.Service.List(models.UserFromContext(r))
+	log.Debugf("Organization.list: %d items found", len(recs))
+	models.JSONResponse(w, r, recs)
+}
+
+func (a *OrganizationEndpoints) create(w http.ResponseWriter, r *http.Request) {
+	log := GetLogger(r)
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		log.Error("Can't read request body")
+		models.ErrorResponseWithStatus(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	var data models.Organization
+	if err := json.Unmarshal(body, &data); err != nil {
+		log.Error("Can't parse request body")
+		models.ErrorResponseWithStatus(w, r, err, http.StatusBadRequest)
+		return
+	}
+
+	resp, err := a.Service.Create(models.UserFromContext(r), data)
+	if err != nil {
+		log.Errorf("Organization.Service.Create: %s", err.Error())
+		models.ErrorResponse(w, r, err)
+		return
+	}
+
+	log.Debugf("Organization: %+v", resp)
+
+	models.JSONResponse(w, r, resp)
+}
+
+func (a *OrganizationEndpoints) update(w http.ResponseWriter, r *http.Request) {
+	log := GetLogger(r)
+	code := chi.URLParam(r, "OrgCode")
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		log.Error("Can't read request body")
+		models.ErrorResponseWithStatus(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	var data models.Organization
+	if err := json.Unmarshal(body, &data); err != nil {
+		log.Error("Can't parse request body")
+		models.ErrorResponseWithStatus(w, r, err, http.StatusBadRequest)
+		return
+	}
+
+	resp, err := a.Service.Update(models.UserFromContext(r), code, data)
+	if err != nil {
+		log.Errorf("Organization.Service.Update: %s", err.Error())
+		models.ErrorResponse(w, r, err)
+		return
+	}
+
+	log.Debugf("Organization: %+v", resp)
+
+	models.JSONResponse(w, r, resp)
+}
+
+func (a *OrganizationEndpoints) get(w http.ResponseWriter, r *http.Request) {
+	log := GetLogger(r)
+	code := chi.URLParam(r, "OrgCode")
+
+	resp, err := a.Service.Get(models.UserFromContext(r), code)
+	if err != nil {
+		log.Errorf("Organization.Service.Get: %s", err.Error())
+		models.ErrorResponse(w, r, err)
+		return
+	}
+
+	log.Debugf("Organization: %+v", resp)
+
+	models.JSONResponse(w, r, resp)
+}
+
+func (a *OrganizationEndpoints) delete(w http.ResponseWriter, r *http.Request) {
+	log := GetLogger(r)
+	code := chi.URLParam(r, "OrgCode")
+
+	if err := a.Service.Delete(models.UserFromContext(r), code); err != nil {
+		log.Errorf("Organization.Service.Delete: %s", err.Error())
+		models.ErrorResponse(w, r, err)
+		return
+	}
+
+	log.Debugf("Organization [%s] deleted", code)
+
+	models.NoContentResponse(w, r)
+}
+
+func (a *OrganizationEndpoints) setMember(w http.ResponseWriter, r *http.Request) {
+	log := GetLogger(r)
+	code := chi.URLParam(r, "OrgCode")
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		log.Error("Can't read request body")
+		models.ErrorResponseWithStatus(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	var data models.OrganizationMember
+	if err := json.Unmarshal(body, &data); err != nil {
+		log.Error("Can't parse request body")
+		models.ErrorResponseWithStatus(w, r, err, http.StatusBadRequest)
+		return
+	}
+	data.UserID = chi.URLParam(r, "UserID")
+
+	resp, err := a.Service.SetMember(models.UserFromContext(r), code, data)
+	if err != nil {
+		log.Errorf("Organization.Service.SetMember: %s", err.Error())
+		models.ErrorResponse(w, r, err)
+		return
+	}
+
+	log.Debugf("Organization [%s] member: %+v", code, data)
+
+	models.JSONResponse(w, r, resp)
+}
+
+func (a *OrganizationEndpoints) removeMember(w http.ResponseWriter, r *http.Request) {
+	log := GetLogger(r)
+	code := chi.URLParam(r, "OrgCode")
+	user := chi.URLParam(r, "UserID")
+
+	if _, err := a.Service.RemoveMember(models.UserFromContext(r), code, user); err != nil {
+		log.Errorf("Organization.Service.RemoveMember: %s", err.Error())
+		models.ErrorResponse(w, r, err)
+		return
+	}
+
+	log.Debugf("Organization [%s] member [%s] removed", code, user)
+
+	models.NoContentResponse(w, r)
+}
+
+func (a *OrganizationEndpoints) setTeam(w http.ResponseWriter, r *http.Request) {
+	log := GetLogger(r)
+	code := chi.URLParam(r, "OrgCode")
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		log.Error("Can't read request body")
+		models.ErrorResponseWithStatus(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	var data models.Team
+	if err := json.Unmarshal(body, &data); err != nil {
+		log.Error("Can't parse request body")
+		models.ErrorResponseWithStatus(w, r, err, http.StatusBadRequest)
+		return
+	}
+	data.Code = chi.URLParam(r, "TeamCode")
+
+	resp, err := a.Service.SetTeam(models.UserFromContext(r), code, data)
+	if err != nil {
+		log.Errorf("Organization.Service.SetTeam: %s", err.Error())
+		models.ErrorResponse(w, r, err)
+		return
+	}
+
+	log.Debugf("Organization [%s] team: %+v", code, data)
+
+	models.JSONResponse(w, r, resp)
+}
+
+func (a *OrganizationEndpoints) removeTeam(w http.ResponseWriter, r *http.Request) {
+	log := GetLogger(r)
+	code := chi.URLParam(r, "OrgCode")
+	team := chi.URLParam(r, "TeamCode")
+
+	if _, err := a.Service.RemoveTeam(models.UserFromContext(r), code, team); err != nil {
+		log.Errorf("Organization.Service.RemoveTeam: %s", err.Error())
+		models.ErrorResponse(w, r, err)
+		return
+	}
+
+	log.Debugf("Organization [%s] team [%s] removed", code, team)
+
+	models.NoContentResponse(w, r)
+}
+
+func (a *OrganizationEndpoints) invitations(w http.ResponseWriter, r *http.Request) {
+	log := GetLogger(r)
+	code := chi.URLParam(r, "OrgCode")
+
+	recs, err := a.Invitations.ListOrganization(models.UserFromContext(r), code)
+	if err != nil {
+		log.Errorf("Invitation.Service.ListOrganization: %s", err.Error())
+		models.ErrorResponse(w, r, err)
+		return
+	}
+
+	log.Debugf("Organization.invitations: %d items found", len(recs))
+	models.JSONResponse(w, r, recs)
+}
+
+func (a *OrganizationEndpoints) invite(w http.ResponseWriter, r *http.Request) {
+	log := GetLogger(r)
+	code := chi.URLParam(r, "OrgCode")
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		log.Error("Can't read request body")
+		models.ErrorResponseWithStatus(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	var data models.InvitationRequest
+	if err := json.Unmarshal(body, &data); err != nil {
+		log.Error("Can't parse request body")
+		models.ErrorResponseWithStatus(w, r, err, http.StatusBadRequest)
+		return
+	}
+	var ttl time.Duration
+	if data.TTL != "" {
+		if ttl, err = time.ParseDuration(data.TTL); err != nil {
+			log.Error("Can't parse invitation ttl")
+			models.ErrorResponseWithStatus(w, r, err, http.StatusBadRequest)
+			return
+		}
+	}
+
+	resp, err := a.Invitations.InviteToOrganization(models.UserFromContext(r), code, data, ttl)
+	if err != nil {
+		log.Errorf("Invitation.Service.InviteToOrganization: %s", err.Error())
+		models.ErrorResponse(w, r, err)
+		return
+	}
+
+	log.Debugf("Organization [%s] invitation: %s", code, resp.ID.Hex())
+
+	models.JSONResponse(w, r, resp)
+}
+
+func (a *OrganizationEndpoints) revoke(w http.ResponseWriter, r *http.Request) {
+	log := GetLogger(r)
+	code := chi.URLParam(r, "OrgCode")
+	id := chi.URLParam(r, "InvitationID")
+
+	if err := a.Invitations.RevokeOrganization(models.UserFromContext(r), code, id); err != nil {
+		log.Errorf("Invitation.Service.RevokeOrganization: %s", err.Error())
+		models.ErrorResponse(w, r, err)
+		return
+	}
+
+	log.Debugf("Organization [%s] invitation [%s] revoked", code, id)
+
+	models.NoContentResponse(w, r)
+}
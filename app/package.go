@@ -0,0 +1,202 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+
+	"bitbucket.org/toggly/toggly-server/models"
+	"bitbucket.org/toggly/toggly-server/service"
+	"github.com/go-chi/chi"
+	"github.com/op/go-logging"
+)
+
+// PackageEndpoints API struct
+type PackageEndpoints struct {
+	Ctx     context.Context
+	Config  *models.Config
+	Logger  *logging.Logger
+	Service *service.Package
+	Access  *service.Access
+	Audit   *service.Audit
+}
+
+// Routes returns api endpoints
+func (a *PackageEndpoints) Routes() chi.Router {
+	router := chi.NewRouter()
+	router.Group(func(group chi.Router) {
+		group.Use(ProjectAccess(a.Access, models.PermissionWrite))
+		group.Get("/", a.list)
+		group.Post("/", a.create)
+		group.Put("/{PackageCode}", a.update)
+		group.Get("/{PackageCode}", a.get)
+		group.Delete("/{PackageCode}", a.delete)
+		group.Get("/{PackageCode}/env/{EnvCode}/param", a.parameters)
+		group.Get("/{PackageCode}/env/{EnvCode}/values", a.values)
+	})
+	return router
+}
+
+func (a *PackageEndpoints) list(w http.ResponseWriter, r *http.Request) {
+	log := GetLogger(r)
+	owner := models.OwnerFromContext(r)
+	project := chi.URLParam(r, "ProjectCode")
+
+	recs, err := a.Service.List(owner, project)
+	if err != nil {
+		log.Errorf("Package.Service.List: %s", err.Error())
+		models.ErrorResponse(w, r, err)
+		return
+	}
+
+	log.Debugf("Package.list: %d items found", len(recs))
+	models.JSONResponse(w, r, recs)
+}
+
+func (a *PackageEndpoints) create(w http.ResponseWriter, r *http.Request) {
+	log := GetLogger(r)
+	owner := models.OwnerFromContext(r)
+	project := chi.URLParam(r, "ProjectCode")
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		log.Error("Can't read request body")
+		models.ErrorResponseWithStatus(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	var data models.Package
+	if err := json.Unmarshal(body, &data); err != nil {
+		log.Error("Can't parse request body")
+		models.ErrorResponseWithStatus(w, r, err, http.StatusBadRequest)
+		return
+	}
+
+	resp, err := a.Service.Create(owner, project, data)
+	if err != nil {
+		log.Errorf("Package.Service.Create: %s", err.Error())
+		models.ErrorResponse(w, r, err)
+		return
+	}
+
+	a.Audit.Record(auditRecord(r, models.AuditEntityPackage, models.AuditActionCreate, project, "", resp.Code), nil, resp)
+
+	log.Debugf("Package: %+v", resp)
+
+	models.JSONResponse(w, r, resp)
+}
+
+func (a *PackageEndpoints) update(w http.ResponseWriter, r *http.Request) {
+	log := GetLogger(r)
+	owner := models.OwnerFromContext(r)
+	project := chi.URLParam(r, "ProjectCode")
+	code := chi.URLParam(r, "PackageCode")
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		log.Error("Can't read request body")
+		models.ErrorResponseWithStatus(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	var data models.Package
+	if err := json.Unmarshal(body, &data); err != nil {
+		log.Error("Can't parse request body")
+		models.ErrorResponseWithStatus(w, r, err, http.StatusBadRequest)
+		return
+	}
+
+	// code is taken from URL and can't be changed
+	data.Code = code
+
+	before, _ := a.Service.Get(owner, project, code)
+
+	resp, err := a.Service.Update(owner, project, data)
+	if err != nil {
+		log.Errorf("Package.Service.Update: %s", err.Error())
+		models.ErrorResponse(w, r, err)
+		return
+	}
+
+	a.Audit.Record(auditRecord(r, models.AuditEntityPackage, models.AuditActionUpdate, project, "", code), before, resp)
+
+	log.Debugf("Package: %+v", resp)
+
+	models.JSONResponse(w, r, resp)
+}
+
+func (a *PackageEndpoints) get(w http.ResponseWriter, r *http.Request) {
+	log := GetLogger(r)
+	owner := models.OwnerFromContext(r)
+	project := chi.URLParam(r, "ProjectCode")
+	code := chi.URLParam(r, "PackageCode")
+
+	resp, err := a.Service.Get(owner, project, code)
+	if err != nil {
+		log.Errorf("Package.Service.Get: %s", err.Error())
+		models.ErrorResponse(w, r, err)
+		return
+	}
+
+	log.Debugf("Package: %+v", resp)
+
+	models.JSONResponse(w, r, resp)
+}
+
+func (a *PackageEndpoints) delete(w http.ResponseWriter, r *http.Request) {
+	log := GetLogger(r)
+	owner := models.OwnerFromContext(r)
+	project := chi.URLParam(r, "ProjectCode")
+	code := chi.URLParam(r, "PackageCode")
+
+	before, _ := a.Service.Get(owner, project, code)
+
+	if err := a.Service.Delete(owner, project, code); err != nil {
+		log.Errorf("Package.Service.Delete: %s", err.Error())
+		models.ErrorResponse(w, r, err)
+		return
+	}
+
+	a.Audit.Record(auditRecord(r, models.AuditEntityPackage, models.AuditActionDelete, project, "", code), before, nil)
+
+	log.Debugf("Package [%s] deleted", code)
+
+	models.NoContentResponse(w, r)
+}
+
+func (a *PackageEndpoints) parameters(w http.ResponseWriter, r *http.Request) {
+	log := GetLogger(r)
+	owner := models.OwnerFromContext(r)
+	project := chi.URLParam(r, "ProjectCode")
+	code := chi.URLParam(r, "PackageCode")
+	env := chi.URLParam(r, "EnvCode")
+
+	recs, err := a.Service.Parameters(owner, project, code, env)
+	if err != nil {
+		log.Errorf("Package.Service.Parameters: %s", err.Error())
+		models.ErrorResponse(w, r, err)
+		return
+	}
+
+	log.Debugf("Package.parameters: %d items found", len(recs))
+	models.JSONResponse(w, r, recs)
+}
+
+func (a *PackageEndpoints) values(w http.ResponseWriter, r *http.Request) {
+	log := GetLogger(r)
+	owner := models.OwnerFromContext(r)
+	project := chi.URLParam(r, "ProjectCode")
+	code := chi.URLParam(r, "PackageCode")
+	env := chi.URLParam(r, "EnvCode")
+
+	instance := r.URL.Query().Get("instance")
+
+	resp, err := a.Service.Values(owner, project, code, env, instance)
+	if err != nil {
+		log.Errorf("Package.Service.Values: %s", err.Error())
+		models.ErrorResponse(w, r, err)
+		return
+	}
+
+	log.Debugf("Package.values: %d items found", len(resp))
+	models.JSONResponse(w, r, resp)
+}
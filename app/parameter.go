@@ -0,0 +1,318 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+
+	"bitbucket.org/toggly/toggly-server/models"
+	"bitbucket.org/toggly/toggly-server/service"
+	"github.com/go-chi/chi"
+	"github.com/op/go-logging"
+)
+
+// ParameterEndpoints API struct
+type ParameterEndpoints struct {
+	Ctx     context.Context
+	Config  *models.Config
+	Logger  *logging.Logger
+	Service *service.Parameter
+	Access  *service.Access
+	Changes *service.Change
+	Audit   *service.Audit
+}
+
+// Routes returns api endpoints
+func (a *ParameterEndpoints) Routes() chi.Router {
+	router := chi.NewRouter()
+	router.Group(func(group chi.Router) {
+		group.Use(ProjectAccess(a.Access, models.PermissionWrite))
+		group.Get("/", a.list)
+		group.Post("/", a.create)
+		group.Put("/{ParamCode}", a.update)
+		group.Get("/{ParamCode}", a.get)
+		group.Delete("/{ParamCode}", a.delete)
+		group.Get("/{ParamCode}/version", a.versions)
+		group.Get("/{ParamCode}/version/{Version}", a.version)
+		group.Post("/{ParamCode}/version/{Version}/rollback", a.rollback)
+		group.Get("/{ParamCode}/diff", a.diff)
+	})
+	return router
+}
+
+func (a *ParameterEndpoints) list(w http.ResponseWriter, r *http.Request) {
+	log := GetLogger(r)
+	owner := models.OwnerFromContext(r)
+	project := chi.URLParam(r, "ProjectCode")
+	env := chi.URLParam(r, "EnvCode")
+
+	recs, err := a.Service.List(owner, project, env)
+	if err != nil {
+		log.Errorf("Parameter.Service.List: %s", err.Error())
+		models.ErrorResponse(w, r, err)
+		return
+	}
+
+	log.Debugf("Parameter.list: %d items found", len(recs))
+	models.JSONResponse(w, r, recs)
+}
+
+func (a *ParameterEndpoints) create(w http.ResponseWriter, r *http.Request) {
+	log := GetLogger(r)
+	owner := models.OwnerFromContext(r)
+	project := chi.URLParam(r, "ProjectCode")
+	env := chi.URLParam(r, "EnvCode")
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		log.Error("Can't read request body")
+		models.ErrorResponseWithStatus(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	var data models.Parameter
+	if err := json.Unmarshal(body, &data); err != nil {
+		log.Error("Can't parse request body")
+		models.ErrorResponseWithStatus(w, r, err, http.StatusBadRequest)
+		return
+	}
+
+	if a.Changes.IsRequired(owner, project, env) {
+		proposeChange(w, r, a.Changes, project, env, models.ChangeRequest{
+			Entity:    models.ChangeEntityParameter,
+			Action:    models.ChangeActionCreate,
+			Code:      data.Code,
+			Parameter: &data,
+		})
+		return
+	}
+
+	resp, err := a.Service.Create(owner, project, env, data)
+	if err != nil {
+		log.Errorf("Parameter.Service.Create: %s", err.Error())
+		models.ErrorResponse(w, r, err)
+		return
+	}
+
+	a.Audit.Record(auditRecord(r, models.AuditEntityParameter, models.AuditActionCreate, project, env, resp.Code), nil, resp)
+
+	log.Debugf("Parameter: %+v", resp)
+
+	models.JSONResponse(w, r, resp)
+}
+
+func (a *ParameterEndpoints) update(w http.ResponseWriter, r *http.Request) {
+	log := GetLogger(r)
+	owner := models.OwnerFromContext(r)
+	project := chi.URLParam(r, "ProjectCode")
+	env := chi.URLParam(r, "EnvCode")
+	code := chi.URLParam(r, "ParamCode")
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		log.Error("Can't read request body")
+		models.ErrorResponseWithStatus(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	var data models.Parameter
+	if err := json.Unmarshal(body, &data); err != nil {
+		log.Error("Can't parse request body")
+		models.ErrorResponseWithStatus(w, r, err, http.StatusBadRequest)
+		return
+	}
+
+	// code is taken from URL and can't be changed
+	data.Code = code
+
+	if a.Changes.IsRequired(owner, project, env) {
+		proposeChange(w, r, a.Changes, project, env, models.ChangeRequest{
+			Entity:    models.ChangeEntityParameter,
+			Action:    models.ChangeActionUpdate,
+			Code:      code,
+			Parameter: &data,
+		})
+		return
+	}
+
+	before, _ := a.Service.Get(owner, project, env, code)
+
+	resp, err := a.Service.Update(owner, project, env, data)
+	if err != nil {
+		log.Errorf("Parameter.Service.Update: %s", err.Error())
+		models.ErrorResponse(w, r, err)
+		return
+	}
+
+	a.Audit.Record(auditRecord(r, models.AuditEntityParameter, models.AuditActionUpdate, project, env, code), before, resp)
+
+	log.Debugf("Parameter: %+v", resp)
+
+	models.JSONResponse(w, r, resp)
+}
+
+func (a *ParameterEndpoints) get(w http.ResponseWriter, r *http.Request) {
+	log := GetLogger(r)
+	owner := models.OwnerFromContext(r)
+	project := chi.URLParam(r, "ProjectCode")
+	env := chi.URLParam(r, "EnvCode")
+	code := chi.URLParam(r, "ParamCode")
+
+	resp, err := a.Service.Get(owner, project, env, code)
+	if err != nil {
+		log.Errorf("Parameter.Service.Get: %s", err.Error())
+		models.ErrorResponse(w, r, err)
+		return
+	}
+
+	log.Debugf("Parameter: %+v", resp)
+
+	models.JSONResponse(w, r, resp)
+}
+
+func (a *ParameterEndpoints) delete(w http.ResponseWriter, r *http.Request) {
+	log := GetLogger(r)
+	owner := models.OwnerFromContext(r)
+	project := chi.URLParam(r, "ProjectCode")
+	env := chi.URLParam(r, "EnvCode")
+	code := chi.URLParam(r, "ParamCode")
+
+	if a.Changes.IsRequired(owner, project, env) {
+		proposeChange(w, r, a.Changes, project, env, models.ChangeRequest{
+			Entity: models.ChangeEntityParameter,
+			Action: models.ChangeActionDelete,
+			Code:   code,
+		})
+		return
+	}
+
+	before, _ := a.Service.Get(owner, project, env, code)
+
+	if err := a.Service.Delete(owner, project, env, code); err != nil {
+		log.Errorf("Parameter.Service.Delete: %s", err.Error())
+		models.ErrorResponse(w, r, err)
+		return
+	}
+
+	a.Audit.Record(auditRecord(r, models.AuditEntityParameter, models.AuditActionDelete, project, env, code), before, nil)
+
+	log.Debugf("Parameter [%s] deleted", code)
+
+	models.NoContentResponse(w, r)
+}
+
+func (a *ParameterEndpoints) versions(w http.ResponseWriter, r *http.Request) {
+	log := GetLogger(r)
+	owner := models.OwnerFromContext(r)
+	project := chi.URLParam(r, "ProjectCode")
+	env := chi.URLParam(r, "EnvCode")
+	code := chi.URLParam(r, "ParamCode")
+
+	recs, err := a.Service.Versions(owner, project, env, code)
+	if err != nil {
+		log.Errorf("Parameter.Service.Versions: %s", err.Error())
+		models.ErrorResponse(w, r, err)
+		return
+	}
+
+	log.Debugf("Parameter.versions: %d items found", len(recs))
+	models.JSONResponse(w, r, recs)
+}
+
+func (a *ParameterEndpoints) version(w http.ResponseWriter, r *http.Request) {
+	log := GetLogger(r)
+	owner := models.OwnerFromContext(r)
+	project := chi.URLParam(r, "ProjectCode")
+	env := chi.URLParam(r, "EnvCode")
+	code := chi.URLParam(r, "ParamCode")
+
+	version, err := strconv.ParseInt(chi.URLParam(r, "Version"), 10, 64)
+	if err != nil {
+		log.Error("Can't parse version number")
+		models.ErrorResponseWithStatus(w, r, err, http.StatusBadRequest)
+		return
+	}
+
+	resp, err := a.Service.Version(owner, project, env, code, version)
+	if err != nil {
+		log.Errorf("Parameter.Service.Version: %s", err.Error())
+		models.ErrorResponse(w, r, err)
+		return
+	}
+
+	log.Debugf("Parameter version: %+v", resp)
+
+	models.JSONResponse(w, r, resp)
+}
+
+func (a *ParameterEndpoints) diff(w http.ResponseWriter, r *http.Request) {
+	log := GetLogger(r)
+	owner := models.OwnerFromContext(r)
+	project := chi.URLParam(r, "ProjectCode")
+	env := chi.URLParam(r, "EnvCode")
+	code := chi.URLParam(r, "ParamCode")
+
+	from, err := strconv.ParseInt(r.URL.Query().Get("from"), 10, 64)
+	if err != nil {
+		log.Error("Can't parse from version number")
+		models.ErrorResponseWithStatus(w, r, err, http.StatusBadRequest)
+		return
+	}
+	to, err := strconv.ParseInt(r.URL.Query().Get("to"), 10, 64)
+	if err != nil {
+		log.Error("Can't parse to version number")
+		models.ErrorResponseWithStatus(w, r, err, http.StatusBadRequest)
+		return
+	}
+
+	resp, err := a.Service.Diff(owner, project, env, code, from, to)
+	if err != nil {
+		log.Errorf("Parameter.Service.Diff: %s", err.Error())
+		models.ErrorResponse(w, r, err)
+		return
+	}
+
+	log.Debugf("Parameter diff: %+v", resp)
+
+	models.JSONResponse(w, r, resp)
+}
+
+func (a *ParameterEndpoints) rollback(w http.ResponseWriter, r *http.Request) {
+	log := GetLogger(r)
+	owner := models.OwnerFromContext(r)
+	project := chi.URLParam(r, "ProjectCode")
+	env := chi.URLParam(r, "EnvCode")
+	code := chi.URLParam(r, "ParamCode")
+
+	version, err := strconv.ParseInt(chi.URLParam(r, "Version"), 10, 64)
+	if err != nil {
+		log.Error("Can't parse version number")
+		models.ErrorResponseWithStatus(w, r, err, http.StatusBadRequest)
+		return
+	}
+
+	if a.Changes.IsRequired(owner, project, env) {
+		proposeChange(w, r, a.Changes, project, env, models.ChangeRequest{
+			Entity:  models.ChangeEntityParameter,
+			Action:  models.ChangeActionRollback,
+			Code:    code,
+			Version: version,
+		})
+		return
+	}
+
+	before, _ := a.Service.Get(owner, project, env, code)
+
+	resp, err := a.Service.Rollback(owner, project, env, code, version)
+	if err != nil {
+		log.Errorf("Parameter.Service.Rollback: %s", err.Error())
+		models.ErrorResponse(w, r, err)
+		return
+	}
+
+	a.Audit.Record(auditRecord(r, models.AuditEntityParameter, models.AuditActionRollback, project, env, code), before, resp)
+
+	log.Debugf("Parameter: %+v", resp)
+
+	models.JSONResponse(w, r, resp)
+}
@@ -0,0 +1,34 @@
+package app
+
+import "encoding/json"
+
+// mergePatch applies JSON merge patch (RFC 7386) to JSON document
+func mergePatch(doc []byte, patch []byte) ([]byte, error) {
+	var target, changes interface{}
+	if err := json.Unmarshal(doc, &target); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(patch, &changes); err != nil {
+		return nil, err
+	}
+	return json.Marshal(applyMergePatch(target, changes))
+}
+
+func applyMergePatch(target interface{}, patch interface{}) interface{} {
+	changes, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	doc, ok := target.(map[string]interface{})
+	if !ok {
+		doc = make(map[string]interface{})
+	}
+	for key, value := range changes {
+		if value == nil {
+			delete(doc, key)
+			continue
+		}
+		doc[key] = applyMergePatch(doc[key], value)
+	}
+	return doc
+}
@@ -11,17 +11,18 @@ import (
 	"bitbucket.org/toggly/toggly-server/models"
 	"bitbucket.org/toggly/toggly-server/service"
 	"github.com/go-chi/chi"
-	dbStore "github.com/nodely/go-mongo-store"
 	"github.com/op/go-logging"
 )
 
 // ProjectEndpoints API struct
 type ProjectEndpoints struct {
-	Dbs     *dbStore.DbStorage
-	Ctx     context.Context
-	Config  *models.Config
-	Logger  *logging.Logger
-	Service *service.Project
+	Ctx         context.Context
+	Config      *models.Config
+	Logger      *logging.Logger
+	Service     *service.Project
+	Invitations *service.Invitation
+	Access      *service.Access
+	Audit       *service.Audit
 }
 
 // Routes returns api endpoints
@@ -30,15 +31,34 @@ func (a *ProjectEndpoints) Routes() chi.Router {
 	router.Group(func(group chi.Router) {
 		group.Get("/", a.list)
 		group.Post("/", a.create)
+	})
+	router.Group(func(group chi.Router) {
+		group.Use(ProjectAccess(a.Access, models.PermissionManage))
 		group.Put("/{ProjectCode}", a.update)
+		group.Patch("/{ProjectCode}", a.patch)
 		group.Get("/{ProjectCode}", a.get)
+		group.Post("/{ProjectCode}/archive", a.archive)
+		group.Post("/{ProjectCode}/restore", a.restore)
+		group.Get("/{ProjectCode}/member", a.members)
+		group.Put("/{ProjectCode}/member/{UserID}", a.setMember)
+		group.Delete("/{ProjectCode}/member/{UserID}", a.removeMember)
+		group.Put("/{ProjectCode}/team/{TeamCode}", a.setMember)
+		group.Delete("/{ProjectCode}/team/{TeamCode}", a.removeMember)
+		group.Get("/{ProjectCode}/invitation", a.invitations)
+		group.Post("/{ProjectCode}/invitation", a.invite)
+		group.Delete("/{ProjectCode}/invitation/{InvitationID}", a.revoke)
+	})
+	router.Group(func(group chi.Router) {
+		group.Use(ProjectAccess(a.Access, models.PermissionOwn))
+		group.Delete("/{ProjectCode}", a.delete)
 	})
 	return router
 }
 
 func (a *ProjectEndpoints) list(w http.ResponseWriter, r *http.Request) {
 	log := GetLogger(r)
-	recs := a.Service.List()
+	owner := models.OwnerFromContext(r)
+	recs := a.Access.Projects(owner, models.UserFromContext(r))
 	log.Debugf("Project.list: %d items found", len(recs))
 	models.JSONResponse(w, r, recs)
 }
@@ -54,14 +74,15 @@ func (a *ProjectEndpoints) create(w http.ResponseWriter, r *http.Request) {
 	var data models.Project
 	if err := json.Unmarshal(body, &data); err != nil {
 		log.Error("Can't parse request body")
-		models.ErrorResponseWithStatus(w, r, err, http.StatusInternalServerError)
+		models.ErrorResponseWithStatus(w, r, err, http.StatusBadRequest)
 		return
 	}
 
 	// fill up default values
 	data.OwnerID = models.OwnerFromContext(r)
 	data.RegDate = time.Now()
-	data.Status = 1
+	data.Status = models.ProjectStatusActive
+	data.Members = []*models.ProjectMember{{UserID: models.UserFromContext(r), Role: models.RoleOwner}}
 
 	// create project
 	resp, err := a.Service.Create(data)
@@ -71,6 +92,8 @@ func (a *ProjectEndpoints) create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	a.Audit.Record(auditRecord(r, models.AuditEntityProject, models.AuditActionCreate, resp.Code, "", resp.Code), nil, resp)
+
 	log.Debugf("Project: %+v", resp)
 
 	models.JSONResponse(w, r, resp)
@@ -78,10 +101,46 @@ func (a *ProjectEndpoints) create(w http.ResponseWriter, r *http.Request) {
 
 func (a *ProjectEndpoints) update(w http.ResponseWriter, r *http.Request) {
 	log := GetLogger(r)
+	owner := models.OwnerFromContext(r)
+	code := chi.URLParam(r, "ProjectCode")
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		log.Error("Can't read request body")
+		models.ErrorResponseWithStatus(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	var data models.Project
+	if err := json.Unmarshal(body, &data); err != nil {
+		log.Error("Can't parse request body")
+		models.ErrorResponseWithStatus(w, r, err, http.StatusBadRequest)
+		return
+	}
+
+	before := a.Service.Get(owner, code)
+
+	// update project
+	resp, err := a.Service.Update(owner, code, data)
+	if err != nil {
+		log.Errorf("Project.Service.Update: %s", err.Error())
+		models.ErrorResponse(w, r, err)
+		return
+	}
+
+	a.Audit.Record(auditRecord(r, models.AuditEntityProject, models.AuditActionUpdate, code, "", code), before, resp)
+
+	log.Debugf("Project: %+v", resp)
+
+	models.JSONResponse(w, r, resp)
+}
+
+func (a *ProjectEndpoints) patch(w http.ResponseWriter, r *http.Request) {
+	log := GetLogger(r)
+	owner := models.OwnerFromContext(r)
 	code := chi.URLParam(r, "ProjectCode")
 
 	// verify project existance
-	if ok := a.Service.IsExist(code); !ok {
+	if ok := a.Service.IsExist(owner, code); !ok {
 		log.Errorf("Project with code [%s] is not found", code)
 		models.NotFoundResponse(w, r, fmt.Sprintf("Project with code [%s] is not found", code))
 		return
@@ -93,26 +152,38 @@ func (a *ProjectEndpoints) update(w http.ResponseWriter, r *http.Request) {
 		models.ErrorResponseWithStatus(w, r, err, http.StatusInternalServerError)
 		return
 	}
-	var data models.Project
-	if err := json.Unmarshal(body, &data); err != nil {
-		log.Error("Can't parse request body")
+
+	// apply patch to current project state
+	before := a.Service.Get(owner, code)
+	current, err := json.Marshal(before)
+	if err != nil {
+		log.Error("Can't serialize project")
 		models.ErrorResponseWithStatus(w, r, err, http.StatusInternalServerError)
 		return
 	}
+	patched, err := mergePatch(current, body)
+	if err != nil {
+		log.Error("Can't apply request body patch")
+		models.ErrorResponseWithStatus(w, r, err, http.StatusBadRequest)
+		return
+	}
+	var data models.Project
+	if err := json.Unmarshal(patched, &data); err != nil {
+		log.Error("Can't parse patched project")
+		models.ErrorResponseWithStatus(w, r, err, http.StatusBadRequest)
+		return
+	}
 
-	// fill up default values
-	data.OwnerID = models.OwnerFromContext(r)
-	data.RegDate = time.Now()
-	data.Status = 1
-
-	// create project
-	resp, err := a.Service.Create(data)
+	// update project
+	resp, err := a.Service.Update(owner, code, data)
 	if err != nil {
-		log.Errorf("Project.Service.Create: %s", err.Error())
+		log.Errorf("Project.Service.Update: %s", err.Error())
 		models.ErrorResponse(w, r, err)
 		return
 	}
 
+	a.Audit.Record(auditRecord(r, models.AuditEntityProject, models.AuditActionUpdate, code, "", code), before, resp)
+
 	log.Debugf("Project: %+v", resp)
 
 	models.JSONResponse(w, r, resp)
@@ -120,18 +191,270 @@ func (a *ProjectEndpoints) update(w http.ResponseWriter, r *http.Request) {
 
 func (a *ProjectEndpoints) get(w http.ResponseWriter, r *http.Request) {
 	log := GetLogger(r)
+	owner := models.OwnerFromContext(r)
 	code := chi.URLParam(r, "ProjectCode")
 
 	// verify project existance
-	if ok := a.Service.IsExist(code); !ok {
+	if ok := a.Service.IsExist(owner, code); !ok {
 		log.Errorf("Project with code [%s] is not found", code)
 		models.NotFoundResponse(w, r, fmt.Sprintf("Project with code [%s] is not found", code))
 		return
 	}
 
-	resp := a.Service.Get(code)
+	resp := a.Service.Get(owner, code)
+
+	log.Debugf("Project: %+v", resp)
+
+	models.JSONResponse(w, r, resp)
+}
+
+func (a *ProjectEndpoints) delete(w http.ResponseWriter, r *http.Request) {
+	log := GetLogger(r)
+	owner := models.OwnerFromContext(r)
+	code := chi.URLParam(r, "ProjectCode")
+
+	before := a.Service.Get(owner, code)
+
+	if err := a.Service.Delete(owner, code); err != nil {
+		log.Errorf("Project.Service.Delete: %s", err.Error())
+		models.ErrorResponse(w, r, err)
+		return
+	}
+
+	a.Audit.Record(auditRecord(r, models.AuditEntityProject, models.AuditActionDelete, code, "", code), before, nil)
+
+	log.Debugf("Project [%s] deleted", code)
+
+	models.NoContentResponse(w, r)
+}
+
+func (a *ProjectEndpoints) archive(w http.ResponseWriter, r *http.Request) {
+	log := GetLogger(r)
+	owner := models.OwnerFromContext(r)
+	code := chi.URLParam(r, "ProjectCode")
+
+	before := a.Service.Get(owner, code)
+
+	resp, err := a.Service.SetStatus(owner, code, models.ProjectStatusDisabled)
+	if err != nil {
+		log.Errorf("Project.Service.SetStatus: %s", err.Error())
+		models.ErrorResponse(w, r, err)
+		return
+	}
+
+	a.Audit.Record(auditRecord(r, models.AuditEntityProject, models.AuditActionUpdate, code, "", code), before, resp)
 
 	log.Debugf("Project: %+v", resp)
 
 	models.JSONResponse(w, r, resp)
 }
+
+func (a *ProjectEndpoints) restore(w http.ResponseWriter, r *http.Request) {
+	log := GetLogger(r)
+	owner := models.OwnerFromContext(r)
+	code := chi.URLParam(r, "ProjectCode")
+
+	before := a.Service.Get(owner, code)
+
+	resp, err := a.Service.SetStatus(owner, code, models.ProjectStatusActive)
+	if err != nil {
+		log.Errorf("Project.Service.SetStatus: %s", err.Error())
+		models.ErrorResponse(w, r, err)
+		return
+	}
+
+	a.Audit.Record(auditRecord(r, models.AuditEntityProject, models.AuditActionUpdate, code, "", code), before, resp)
+
+	log.Debugf("Project: %+v", resp)
+
+	models.JSONResponse(w, r, resp)
+}
+
+func (a *ProjectEndpoints) members(w http.ResponseWriter, r *http.Request) {
+	log := GetLogger(r)
+	owner := models.OwnerFromContext(r)
+	code := chi.URLParam(r, "ProjectCode")
+
+	// verify project existance
+	if ok := a.Service.IsExist(owner, code); !ok {
+		log.Errorf("Project with code [%s] is not found", code)
+		models.NotFoundResponse(w, r, fmt.Sprintf("Project with code [%s] is not found", code))
+		return
+	}
+
+	resp := a.Service.Get(owner, code).Members
+	if resp == nil {
+		resp = make([]*models.ProjectMember, 0)
+	}
+
+	log.Debugf("Project.members: %d items found", len(resp))
+
+	models.JSONResponse(w, r, resp)
+}
+
+func (a *ProjectEndpoints) setMember(w http.ResponseWriter, r *http.Request) {
+	log := GetLogger(r)
+	owner := models.OwnerFromContext(r)
+	code := chi.URLParam(r, "ProjectCode")
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		log.Error("Can't read request body")
+		models.ErrorResponseWithStatus(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	var data models.ProjectMember
+	if err := json.Unmarshal(body, &data); err != nil {
+		log.Error("Can't parse request body")
+		models.ErrorResponseWithStatus(w, r, err, http.StatusBadRequest)
+		return
+	}
+	data.UserID = chi.URLParam(r, "UserID")
+	data.Team = chi.URLParam(r, "TeamCode")
+
+	// only owners grant or revoke owner role
+	if data.Role == models.RoleOwner || a.grantedRole(owner, code, data) == models.RoleOwner {
+		if err := a.Access.Check(owner, models.UserFromContext(r), code, "", models.PermissionOwn); err != nil {
+			log.Errorf("Access denied: %s", err.Error())
+			models.ErrorResponse(w, r, err)
+			return
+		}
+	}
+
+	before := a.Service.Get(owner, code)
+
+	resp, err := a.Service.SetMember(owner, code, data)
+	if err != nil {
+		log.Errorf("Project.Service.SetMember: %s", err.Error())
+		models.ErrorResponse(w, r, err)
+		return
+	}
+
+	a.Audit.Record(auditRecord(r, models.AuditEntityProject, models.AuditActionUpdate, code, "", code), before, resp)
+
+	log.Debugf("Project [%s] member: %+v", code, data)
+
+	models.JSONResponse(w, r, resp.Members)
+}
+
+func (a *ProjectEndpoints) removeMember(w http.ResponseWriter, r *http.Request) {
+	log := GetLogger(r)
+	owner := models.OwnerFromContext(r)
+	code := chi.URLParam(r, "ProjectCode")
+	data := models.ProjectMember{UserID: chi.URLParam(r, "UserID"), Team: chi.URLParam(r, "TeamCode")}
+
+	// only owners revoke owner role
+	if a.grantedRole(owner, code, data) == models.RoleOwner {
+		if err := a.Access.Check(owner, models.UserFromContext(r), code, "", models.PermissionOwn); err != nil {
+			log.Errorf("Access denied: %s", err.Error())
+			models.ErrorResponse(w, r, err)
+			return
+		}
+	}
+
+	before := a.Service.Get(owner, code)
+
+	resp, err := a.Service.RemoveMember(owner, code, data)
+	if err != nil {
+		log.Errorf("Project.Service.RemoveMember: %s", err.Error())
+		models.ErrorResponse(w, r, err)
+		return
+	}
+
+	a.Audit.Record(auditRecord(r, models.AuditEntityProject, models.AuditActionUpdate, code, "", code), before, resp)
+
+	log.Debugf("Project [%s] member [%s%s] removed", code, data.UserID, data.Team)
+
+	models.NoContentResponse(w, r)
+}
+
+// grantedRole returns role user or team already has in project
+func (a *ProjectEndpoints) grantedRole(owner string, code string, data models.ProjectMember) string {
+	if data.Team == "" {
+		return a.Access.Role(owner, data.UserID, code)
+	}
+	if grant := a.Service.Get(owner, code).TeamMember(data.Team); grant != nil {
+		return grant.Role
+	}
+	return ""
+}
+
+func (a *ProjectEndpoints) invitations(w http.ResponseWriter, r *http.Request) {
+	log := GetLogger(r)
+	owner := models.OwnerFromContext(r)
+	code := chi.URLParam(r, "ProjectCode")
+
+	recs, err := a.Invitations.ListProject(owner, code)
+	if err != nil {
+		log.Errorf("Invitation.Service.ListProject: %s", err.Error())
+		models.ErrorResponse(w, r, err)
+		return
+	}
+
+	log.Debugf("Project.invitations: %d items found", len(recs))
+	models.JSONResponse(w, r, recs)
+}
+
+func (a *ProjectEndpoints) invite(w http.ResponseWriter, r *http.Request) {
+	log := GetLogger(r)
+	owner := models.OwnerFromContext(r)
+	code := chi.URLParam(r, "ProjectCode")
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		log.Error("Can't read request body")
+		models.ErrorResponseWithStatus(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	var data models.InvitationRequest
+	if err := json.Unmarshal(body, &data); err != nil {
+		log.Error("Can't parse request body")
+		models.ErrorResponseWithStatus(w, r, err, http.StatusBadRequest)
+		return
+	}
+	var ttl time.Duration
+	if data.TTL != "" {
+		if ttl, err = time.ParseDuration(data.TTL); err != nil {
+			log.Error("Can't parse invitation ttl")
+			models.ErrorResponseWithStatus(w, r, err, http.StatusBadRequest)
+			return
+		}
+	}
+
+	// only owners invite owners
+	if data.Role == models.RoleOwner {
+		if err := a.Access.Check(owner, models.UserFromContext(r), code, "", models.PermissionOwn); err != nil {
+			log.Errorf("Access denied: %s", err.Error())
+			models.ErrorResponse(w, r, err)
+			return
+		}
+	}
+
+	resp, err := a.Invitations.InviteToProject(owner, models.UserFromContext(r), code, data, ttl)
+	if err != nil {
+		log.Errorf("Invitation.Service.InviteToProject: %s", err.Error())
+		models.ErrorResponse(w, r, err)
+		return
+	}
+
+	log.Debugf("Project [%s] invitation: %s", code, resp.ID.Hex())
+
+	models.JSONResponse(w, r, resp)
+}
+
+func (a *ProjectEndpoints) revoke(w http.ResponseWriter, r *http.Request) {
+	log := GetLogger(r)
+	owner := models.OwnerFromContext(r)
+	code := chi.URLParam(r, "ProjectCode")
+	id := chi.URLParam(r, "InvitationID")
+
+	if err := a.Invitations.RevokeProject(owner, code, id); err != nil {
+		log.Errorf("Invitation.Service.RevokeProject: %s", err.Error())
+		models.ErrorResponse(w, r, err)
+		return
+	}
+
+	log.Debugf("Project [%s] invitation [%s] revoked", code, id)
+
+	models.NoContentResponse(w, r)
+}
@@ -0,0 +1,118 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+
+	"bitbucket.org/toggly/toggly-server/models"
+	"bitbucket.org/toggly/toggly-server/service"
+	"github.com/go-chi/chi"
+	"github.com/op/go-logging"
+)
+
+// PromotionEndpoints API struct
+type PromotionEndpoints struct {
+	Ctx     context.Context
+	Config  *models.Config
+	Logger  *logging.Logger
+	Service *service.Promotion
+	Access  *service.Access
+	Changes *service.Change
+	Audit   *service.Audit
+}
+
+// Routes returns api endpoints
+func (a *PromotionEndpoints) Routes() chi.Router {
+	router := chi.NewRouter()
+	router.Group(func(group chi.Router) {
+		group.Use(ProjectAccess(a.Access, models.PermissionRead))
+		group.Get("/{TargetCode}", a.preview)
+		group.Post("/{TargetCode}", a.apply)
+	})
+	return router
+}
+
+func (a *PromotionEndpoints) preview(w http.ResponseWriter, r *http.Request) {
+	log := GetLogger(r)
+	owner := models.OwnerFromContext(r)
+	project := chi.URLParam(r, "ProjectCode")
+	source := chi.URLParam(r, "EnvCode")
+	target := chi.URLParam(r, "TargetCode")
+
+	resp, err := a.Service.Preview(owner, project, source, target)
+	if err != nil {
+		log.Errorf("Promotion.Service.Preview: %s", err.Error())
+		models.ErrorResponse(w, r, err)
+		return
+	}
+
+	log.Debugf("Promotion.preview: %d items found", len(resp.Items))
+	models.JSONResponse(w, r, resp)
+}
+
+func (a *PromotionEndpoints) apply(w http.ResponseWriter, r *http.Request) {
+	log := GetLogger(r)
+	owner := models.OwnerFromContext(r)
+	project := chi.URLParam(r, "ProjectCode")
+	source := chi.URLParam(r, "EnvCode")
+	target := chi.URLParam(r, "TargetCode")
+
+	// source is only read, target environment is changed
+	if err := a.Access.Check(owner, models.UserFromContext(r), project, target, models.PermissionWrite); err != nil {
+		log.Errorf("Access denied: %s", err.Error())
+		models.ErrorResponse(w, r, err)
+		return
+	}
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		log.Error("Can't read request body")
+		models.ErrorResponseWithStatus(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	var data models.PromotionRequest
+	if len(body) != 0 {
+		if err := json.Unmarshal(body, &data); err != nil {
+			log.Error("Can't parse request body")
+			models.ErrorResponseWithStatus(w, r, err, http.StatusBadRequest)
+			return
+		}
+	}
+
+	// confirmed promotion to protected environment waits for approval
+	if data.Confirm && a.Changes.IsRequired(owner, project, target) {
+		prepared, err := a.Service.Prepare(owner, project, source, target, data)
+		if err != nil {
+			log.Errorf("Promotion.Service.Prepare: %s", err.Error())
+			models.ErrorResponse(w, r, err)
+			return
+		}
+		proposeChange(w, r, a.Changes, project, target, models.ChangeRequest{
+			Entity:    models.ChangeEntityPromotion,
+			Action:    models.ChangeActionPromote,
+			Source:    source,
+			Promotion: &data,
+			Preview:   prepared,
+		})
+		return
+	}
+
+	resp, err := a.Service.Apply(owner, project, source, target, data)
+	if resp != nil {
+		// partially applied promotion is audited as well
+		for _, item := range resp.Items {
+			a.Audit.Record(auditRecord(r, models.AuditEntityParameter, item.Action, project, target, item.Code), item.Before, item.After)
+		}
+	}
+	if err != nil {
+		log.Errorf("Promotion.Service.Apply: %s", err.Error())
+		models.ErrorResponse(w, r, err)
+		return
+	}
+
+	log.Debugf("Promotion: %d items applied", len(resp.Items))
+
+	models.JSONResponse(w, r, resp)
+}
@@ -0,0 +1,147 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"bitbucket.org/toggly/toggly-server/models"
+	"bitbucket.org/toggly/toggly-server/service"
+	"github.com/go-chi/chi"
+	"github.com/op/go-logging"
+)
+
+// SDKKeyEndpoints API struct
+type SDKKeyEndpoints struct {
+	Ctx     context.Context
+	Config  *models.Config
+	Logger  *logging.Logger
+	Service *service.SDKKey
+	Access  *service.Access
+}
+
+// Routes returns api endpoints
+func (a *SDKKeyEndpoints) Routes() chi.Router {
+	router := chi.NewRouter()
+	router.Group(func(group chi.Router) {
+		group.Use(ProjectAccess(a.Access, models.PermissionManage))
+		group.Get("/", a.list)
+		group.Post("/", a.create)
+		group.Post("/rotate", a.rotate)
+		group.Delete("/{KeyID}", a.delete)
+	})
+	return router
+}
+
+func (a *SDKKeyEndpoints) list(w http.ResponseWriter, r *http.Request) {
+	log := GetLogger(r)
+	owner := models.OwnerFromContext(r)
+	project := chi.URLParam(r, "ProjectCode")
+	env := chi.URLParam(r, "EnvCode")
+
+	recs, err := a.Service.List(owner, project, env)
+	if err != nil {
+		log.Errorf("SDKKey.Service.List: %s", err.Error())
+		models.ErrorResponse(w, r, err)
+		return
+	}
+
+	log.Debugf("SDKKey.list: %d items found", len(recs))
+	models.JSONResponse(w, r, recs)
+}
+
+func (a *SDKKeyEndpoints) create(w http.ResponseWriter, r *http.Request) {
+	log := GetLogger(r)
+	owner := models.OwnerFromContext(r)
+	project := chi.URLParam(r, "ProjectCode")
+	env := chi.URLParam(r, "EnvCode")
+
+	data, err := readSDKKeyRequest(r)
+	if err != nil {
+		log.Error("Can't parse request body")
+		models.ErrorResponseWithStatus(w, r, err, http.StatusBadRequest)
+		return
+	}
+
+	resp, err := a.Service.Create(owner, project, env, data.Kind)
+	if err != nil {
+		log.Errorf("SDKKey.Service.Create: %s", err.Error())
+		models.ErrorResponse(w, r, err)
+		return
+	}
+
+	log.Debugf("SDKKey [%s] created", resp.Prefix)
+
+	models.JSONResponse(w, r, resp)
+}
+
+func (a *SDKKeyEndpoints) rotate(w http.ResponseWriter, r *http.Request) {
+	log := GetLogger(r)
+	owner := models.OwnerFromContext(r)
+	project := chi.URLParam(r, "ProjectCode")
+	env := chi.URLParam(r, "EnvCode")
+
+	data, err := readSDKKeyRequest(r)
+	if err != nil {
+		log.Error("Can't parse request body")
+		models.ErrorResponseWithStatus(w, r, err, http.StatusBadRequest)
+		return
+	}
+	var grace time.Duration
+	if data.Grace != "" {
+		if grace, err = time.ParseDuration(data.Grace); err != nil {
+			log.Error("Can't parse grace period")
+			models.ErrorResponseWithStatus(w, r, err, http.StatusBadRequest)
+			return
+		}
+	}
+
+	resp, err := a.Service.Rotate(owner, project, env, data.Kind, grace)
+	if err != nil {
+		log.Errorf("SDKKey.Service.Rotate: %s", err.Error())
+		models.ErrorResponse(w, r, err)
+		return
+	}
+
+	log.Debugf("SDKKey [%s] rotated", resp.Prefix)
+
+	models.JSONResponse(w, r, resp)
+}
+
+func (a *SDKKeyEndpoints) delete(w http.ResponseWriter, r *http.Request) {
+	log := GetLogger(r)
+	owner := models.OwnerFromContext(r)
+	project := chi.URLParam(r, "ProjectCode")
+	env := chi.URLParam(r, "EnvCode")
+	id := chi.URLParam(r, "KeyID")
+
+	if err := a.Service.Delete(owner, project, env, id); err != nil {
+		log.Errorf("SDKKey.Service.Delete: %s", err.Error())
+		models.ErrorResponse(w, r, err)
+		return
+	}
+
+	log.Debugf("SDKKey [%s] deleted", id)
+
+	models.NoContentResponse(w, r)
+}
+
+func readSDKKeyRequest(r *http.Request) (*models.SDKKeyRequest, error) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+	var data models.SDKKeyRequest
+	if err := json.Unmarshal(body, &data); err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+// sdkKeyAllows checks that request SDK key, if any, belongs to environment
+func sdkKeyAllows(r *http.Request, project string, env string) bool {
+	key := models.SDKKeyFromContext(r)
+	return key == nil || (key.Project == project && key.Environment == env)
+}
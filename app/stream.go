@@ -0,0 +1,136 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"bitbucket.org/toggly/toggly-server/models"
+	"bitbucket.org/toggly/toggly-server/service"
+	"github.com/go-chi/chi"
+	"github.com/op/go-logging"
+)
+
+// streamHeartbeat is an interval of keep alive comments sent to idle streams
+const streamHeartbeat = 15 * time.Second
+
+// StreamEndpoints API struct, Server-Sent Events stream of configuration changes
+type StreamEndpoints struct {
+	Ctx     context.Context
+	Config  *models.Config
+	Logger  *logging.Logger
+	Service *service.Evaluation
+	Events  *service.Events
+	Access  *service.Access
+}
+
+// Routes returns api endpoints
+func (a *StreamEndpoints) Routes() chi.Router {
+	router := chi.NewRouter()
+	router.Group(func(group chi.Router) {
+		group.Use(ProjectAccess(a.Access, models.PermissionRead))
+		group.Get("/", a.stream)
+	})
+	return router
+}
+
+func (a *StreamEndpoints) stream(w http.ResponseWriter, r *http.Request) {
+	log := GetLogger(r)
+	owner := models.OwnerFromContext(r)
+	project := chi.URLParam(r, "ProjectCode")
+	env := chi.URLParam(r, "EnvCode")
+
+	if !sdkKeyAllows(r, project, env) {
+		log.Error("SDK key belongs to another environment")
+		models.ForbiddenResponse(w, r, "SDK key belongs to another environment")
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		log.Error("Streaming is not supported")
+		models.ErrorResponseWithStatus(w, r, fmt.Errorf("Streaming is not supported"), http.StatusInternalServerError)
+		return
+	}
+
+	// verify project and environment existance
+	key := models.SDKKeyFromContext(r)
+	if _, err := a.Service.KeyValues(key, owner, project, env, "", "", nil); err != nil {
+		log.Errorf("Evaluation.Service.KeyValues: %s", err.Error())
+		models.ErrorResponse(w, r, err)
+		return
+	}
+
+	lastID, _ := strconv.ParseUint(r.Header.Get("Last-Event-ID"), 10, 64)
+	sub := a.Events.Subscribe(lastID, func(evt *models.Event) bool {
+		return evt.OwnerID == owner && evt.Project == project && (evt.Environment == "" || evt.Environment == env) && key.Receives(evt)
+	})
+	defer sub.Cancel()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	if sub.Resumed {
+		for _, evt := range sub.Missed {
+			if err := writeEvent(w, evt.ID, "change", evt); err != nil {
+				return
+			}
+		}
+	} else {
+		// client starts from scratch or missed too much, send current state
+		values, err := a.Service.KeyValues(key, owner, project, env, "", "", nil)
+		if err != nil {
+			log.Errorf("Evaluation.Service.KeyValues: %s", err.Error())
+			return
+		}
+		if err := writeEvent(w, sub.LastID, "snapshot", values); err != nil {
+			return
+		}
+	}
+	flusher.Flush()
+
+	log.Debugf("Stream [%s/%s] opened", project, env)
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case evt, ok := <-sub.C:
+			if !ok {
+				log.Debugf("Stream [%s/%s] dropped", project, env)
+				return
+			}
+			if err := writeEvent(w, evt.ID, "change", evt); err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+		case <-r.Context().Done():
+			log.Debugf("Stream [%s/%s] closed by client", project, env)
+			return
+		case <-a.Ctx.Done():
+			log.Debugf("Stream [%s/%s] closed on shutdown", project, env)
+			return
+		}
+		flusher.Flush()
+	}
+}
+
+// writeEvent writes Server-Sent Event with JSON data
+func writeEvent(w http.ResponseWriter, id uint64, event string, data interface{}) error {
+	body, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", id, event, body)
+	return err
+}
@@ -0,0 +1,301 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"bitbucket.org/toggly/toggly-server/models"
+	"bitbucket.org/toggly/toggly-server/service"
+	"github.com/go-chi/chi"
+	"github.com/gorilla/websocket"
+	"github.com/op/go-logging"
+	"gopkg.in/toggly/go-utils.v2"
+)
+
+// WebSocket message types
+const (
+	WSMessageSubscribe   = "subscribe"
+	WSMessageUnsubscribe = "unsubscribe"
+	WSMessageSnapshot    = "snapshot"
+	WSMessageChange      = "change"
+	WSMessageError       = "error"
+)
+
+// WSMessage struct, client requests and server notifications
+type WSMessage struct {
+	Type        string                 `json:"type"`
+	Project     string                 `json:"project,omitempty"`
+	Environment string                 `json:"environment,omitempty"`
+	Package     string                 `json:"package,omitempty"`
+	Instance    string                 `json:"instance,omitempty"`
+	Context     map[string]interface{} `json:"context,omitempty"`
+	Values      map[string]interface{} `json:"values,omitempty"`
+	Event       *models.Event          `json:"event,omitempty"`
+	Error       string                 `json:"error,omitempty"`
+}
+
+// key identifies subscription within connection
+func (m *WSMessage) key() string {
+	return strings.Join([]string{m.Project, m.Environment, m.Package, m.Instance}, "/")
+}
+
+// wsBuffer is a number of outgoing messages queued per connection
+const wsBuffer = 64
+
+// WebSocketEndpoints API struct, subscriptions to configuration changes over WebSocket
+type WebSocketEndpoints struct {
+	Ctx     context.Context
+	Config  *models.Config
+	Logger  *logging.Logger
+	Service *service.Evaluation
+	Events  *service.Events
+	Access  *service.Access
+}
+
+// Routes returns api endpoints
+func (a *WebSocketEndpoints) Routes() chi.Router {
+	router := chi.NewRouter()
+	router.Group(func(group chi.Router) {
+		group.Get("/", a.connect)
+	})
+	return router
+}
+
+// upgrader accepts connections from configured origins
+func (a *WebSocketEndpoints) upgrader() *websocket.Upgrader {
+	return &websocket.Upgrader{
+		ReadBufferSize:  1024,
+		WriteBufferSize: 1024,
+		CheckOrigin:     a.checkOrigin,
+	}
+}
+
+// checkOrigin allows clients without origin, they are not browsers,
+// and browser origins from configuration or the same origin by default
+func (a *WebSocketEndpoints) checkOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	if len(a.Config.AllowedOrigins) == 0 {
+		u, err := url.Parse(origin)
+		return err == nil && strings.EqualFold(u.Host, r.Host)
+	}
+	for _, allowed := range a.Config.AllowedOrigins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// wsConnection serves single client connection
+type wsConnection struct {
+	endpoints *WebSocketEndpoints
+	log       *utils.StructuredLogger
+	owner     string
+	user      string
+	key       *models.SDKKey
+	out       chan *WSMessage
+	stop      chan struct{}
+	subs      map[string]*wsSubscription
+}
+
+// wsSubscription struct, finished is closed when forwarding stops
+// so the key may be subscribed again after broker drops subscription
+type wsSubscription struct {
+	sub       *service.Subscription
+	cancelled chan struct{}
+	finished  chan struct{}
+}
+
+func (s *wsSubscription) cancel() {
+	close(s.cancelled)
+	s.sub.Cancel()
+}
+
+func (a *WebSocketEndpoints) connect(w http.ResponseWriter, r *http.Request) {
+	log := GetLogger(r)
+	owner := models.OwnerFromContext(r)
+
+	conn, err := a.upgrader().Upgrade(w, r, nil)
+	if err != nil {
+		// upgrader has already responded with error
+		log.Errorf("WebSocket upgrade: %s", err.Error())
+		return
+	}
+	defer conn.Close()
+
+	c := &wsConnection{
+		endpoints: a,
+		log:       log,
+		owner:     owner,
+		user:      models.UserFromContext(r),
+		key:       models.SDKKeyFromContext(r),
+		out:       make(chan *WSMessage, wsBuffer),
+		stop:      make(chan struct{}),
+		subs:      make(map[string]*wsSubscription),
+	}
+	defer close(c.stop)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer c.unsubscribeAll()
+		for {
+			var msg WSMessage
+			if err := conn.ReadJSON(&msg); err != nil {
+				return
+			}
+			c.handle(&msg)
+		}
+	}()
+
+	log.Debug("WebSocket connection opened")
+
+	ping := time.NewTicker(streamHeartbeat)
+	defer ping.Stop()
+
+	for {
+		select {
+		case msg := <-c.out:
+			if err := conn.WriteJSON(msg); err != nil {
+				return
+			}
+		case <-ping.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(streamHeartbeat)); err != nil {
+				return
+			}
+		case <-done:
+			log.Debug("WebSocket connection closed by client")
+			return
+		case <-a.Ctx.Done():
+			conn.WriteControl(websocket.CloseMessage,
+				websocket.FormatCloseMessage(websocket.CloseGoingAway, "server shutdown"),
+				time.Now().Add(time.Second))
+			log.Debug("WebSocket connection closed on shutdown")
+			return
+		}
+	}
+}
+
+// send queues message unless connection is closing
+func (c *wsConnection) send(msg *WSMessage) {
+	select {
+	case c.out <- msg:
+	case <-c.stop:
+	}
+}
+
+func (c *wsConnection) sendError(err error) {
+	c.send(&WSMessage{Type: WSMessageError, Error: err.Error()})
+}
+
+func (c *wsConnection) handle(msg *WSMessage) {
+	// SDK key defines project and environment by itself
+	if c.key != nil && msg.Project == "" && msg.Environment == "" {
+		msg.Project = c.key.Project
+		msg.Environment = c.key.Environment
+	}
+	switch msg.Type {
+	case WSMessageSubscribe:
+		c.subscribe(msg)
+	case WSMessageUnsubscribe:
+		if sub, ok := c.subs[msg.key()]; ok {
+			sub.cancel()
+			delete(c.subs, msg.key())
+		}
+	case WSMessageSnapshot:
+		c.snapshot(msg)
+	default:
+		c.sendError(models.ErrBadRequest("Unknown message type"))
+	}
+}
+
+// snapshot sends current values of subscription,
+// users are checked for every subscription as connection is not bound to project
+func (c *wsConnection) snapshot(req *WSMessage) bool {
+	if c.key != nil && (c.key.Project != req.Project || c.key.Environment != req.Environment) {
+		c.sendError(models.ErrForbidden("SDK key belongs to another environment"))
+		return false
+	}
+	if c.key == nil {
+		if err := c.endpoints.Access.Check(c.owner, c.user, req.Project, req.Environment, models.PermissionRead); err != nil {
+			c.sendError(err)
+			return false
+		}
+	}
+	values, err := c.endpoints.Service.KeyValues(c.key, c.owner, req.Project, req.Environment, req.Package, req.Instance, req.Context)
+	if err != nil {
+		c.sendError(err)
+		return false
+	}
+	c.send(&WSMessage{
+		Type:        WSMessageSnapshot,
+		Project:     req.Project,
+		Environment: req.Environment,
+		Package:     req.Package,
+		Instance:    req.Instance,
+		Values:      values,
+	})
+	return true
+}
+
+func (c *wsConnection) subscribe(req *WSMessage) {
+	if ws, ok := c.subs[req.key()]; ok {
+		select {
+		case <-ws.finished:
+			// dropped by broker, replaced with new subscription
+			delete(c.subs, req.key())
+		default:
+			c.snapshot(req)
+			return
+		}
+	}
+	owner, key := c.owner, c.key
+	sub := c.endpoints.Events.Subscribe(0, func(evt *models.Event) bool {
+		return evt.OwnerID == owner && evt.Project == req.Project && (evt.Environment == "" || evt.Environment == req.Environment) && key.Receives(evt)
+	})
+	if !c.snapshot(req) {
+		sub.Cancel()
+		return
+	}
+	ws := &wsSubscription{sub: sub, cancelled: make(chan struct{}), finished: make(chan struct{})}
+	c.subs[req.key()] = ws
+
+	c.log.Debugf("WebSocket subscribed to [%s]", req.key())
+
+	// forward changes followed by fresh snapshot
+	go func() {
+		defer func() {
+			close(ws.finished)
+			select {
+			case <-ws.cancelled:
+			default:
+				c.sendError(models.ErrConflict(fmt.Sprintf("Subscription [%s] is dropped, subscribe again", req.key())))
+			}
+		}()
+		for evt := range sub.C {
+			c.send(&WSMessage{
+				Type:        WSMessageChange,
+				Project:     req.Project,
+				Environment: req.Environment,
+				Package:     req.Package,
+				Instance:    req.Instance,
+				Event:       evt,
+			})
+			c.snapshot(req)
+		}
+	}()
+}
+
+func (c *wsConnection) unsubscribeAll() {
+	for key, sub := range c.subs {
+		sub.cancel()
+		delete(c.subs, key)
+	}
+}
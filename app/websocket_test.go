@@ -0,0 +1,119 @@
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"bitbucket.org/toggly/toggly-server/models"
+	"bitbucket.org/toggly/toggly-server/service"
+	"bitbucket.org/toggly/toggly-server/storage"
+	"github.com/op/go-logging"
+	"gopkg.in/toggly/go-utils.v2"
+)
+
+func newTestConnection(t *testing.T) (*wsConnection, *service.Events) {
+	st, err := storage.NewEmbeddedStorage(&models.Storage{Driver: storage.DriverEmbedded, Connection: ":memory:"})
+	if err != nil {
+		t.Fatalf("embedded storage: %s", err.Error())
+	}
+	log := logging.MustGetLogger("test")
+	if _, err := (&service.Project{Storage: st, Logger: log}).Create(models.Project{Code: "p1", Name: "P1", OwnerID: "acme", Status: models.ProjectStatusActive}); err != nil {
+		t.Fatalf("project: %s", err.Error())
+	}
+	if _, err := (&service.Environment{Storage: st, Logger: log}).Create("acme", "p1", models.Environment{Code: "dev"}); err != nil {
+		t.Fatalf("environment: %s", err.Error())
+	}
+	events := service.NewEvents(1000)
+	endpoints := &WebSocketEndpoints{
+		Ctx:     context.Background(),
+		Config:  &models.Config{},
+		Logger:  log,
+		Service: &service.Evaluation{Storage: st, Logger: log},
+		Events:  events,
+		Access:  &service.Access{Storage: st, Logger: log},
+	}
+	return &wsConnection{
+		endpoints: endpoints,
+		log:       &utils.StructuredLogger{Logger: log},
+		owner:     "acme",
+		user:      "acme",
+		out:       make(chan *WSMessage, wsBuffer),
+		stop:      make(chan struct{}),
+		subs:      make(map[string]*wsSubscription),
+	}, events
+}
+
+// nextMessage waits for outgoing message of given type skipping others
+func nextMessage(t *testing.T, c *wsConnection, kind string) *WSMessage {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case msg := <-c.out:
+			if msg.Type == kind {
+				return msg
+			}
+		case <-timeout:
+			t.Fatalf("no [%s] message", kind)
+			return nil
+		}
+	}
+}
+
+func TestSubscribeAgainAfterDrop(t *testing.T) {
+	c, events := newTestConnection(t)
+	defer close(c.stop)
+	defer c.unsubscribeAll()
+
+	subscribe := &WSMessage{Type: WSMessageSubscribe, Project: "p1", Environment: "dev"}
+	publish := func() {
+		events.Publish(&models.Event{
+			OwnerID:     "acme",
+			Project:     "p1",
+			Environment: "dev",
+			Entity:      models.EventEntityParameter,
+			Action:      models.EventActionUpdate,
+			Code:        "f",
+		})
+	}
+
+	c.handle(subscribe)
+	nextMessage(t, c, WSMessageSnapshot)
+
+	// nobody reads connection queue, subscriber falls behind and is dropped
+	for i := 0; i < 4*wsBuffer; i++ {
+		publish()
+	}
+	nextMessage(t, c, WSMessageError)
+
+	c.handle(subscribe)
+	nextMessage(t, c, WSMessageSnapshot)
+	publish()
+	if msg := nextMessage(t, c, WSMessageChange); msg.Event.Code != "f" {
+		t.Errorf("unexpected event: %+v", msg.Event)
+	}
+}
+
+func TestClientKeyReceivesClientParameters(t *testing.T) {
+	c, events := newTestConnection(t)
+	defer close(c.stop)
+	defer c.unsubscribeAll()
+	c.key = &models.SDKKey{Project: "p1", Environment: "dev", Kind: models.SDKKeyClient}
+
+	c.handle(&WSMessage{Type: WSMessageSubscribe})
+	nextMessage(t, c, WSMessageSnapshot)
+
+	for _, evt := range []*models.Event{
+		{Entity: models.EventEntityParameter, Code: "server"},
+		{Entity: models.EventEntityOverride, Code: "client", Instance: "u1", Client: true},
+		{Entity: models.EventEntityPackage, Code: "web"},
+		{Entity: models.EventEntityParameter, Code: "client", Client: true},
+	} {
+		evt.OwnerID, evt.Project, evt.Environment, evt.Action = "acme", "p1", "dev", models.EventActionUpdate
+		events.Publish(evt)
+	}
+	if msg := nextMessage(t, c, WSMessageChange); msg.Event.Code != "client" || msg.Event.Entity != models.EventEntityParameter {
+		t.Errorf("client key receives event: %+v", msg.Event)
+	}
+}
@@ -9,7 +9,7 @@ import (
 
 	"bitbucket.org/toggly/toggly-server/app"
 	"bitbucket.org/toggly/toggly-server/models"
-	dbStore "github.com/nodely/go-mongo-store"
+	"bitbucket.org/toggly/toggly-server/storage"
 	"github.com/op/go-logging"
 	"gopkg.in/nodely/mongo-session.v3"
 	"gopkg.in/session.v3"
@@ -53,40 +53,52 @@ func main() {
 
 	config := loadConfigs(os.Getenv("APP_CONFIG_PATH"))
 
-	// connects to session storage
-	mgoStore, err := mongo.NewMongoStore(&mongo.Options{
-		Connection: config.Storage.Connection,
-		DB:         config.Storage.Name,
-		Collection: "sessions",
-		Logger:     log,
-	})
-	if err != nil {
-		log.Errorf(err.Error())
-		os.Exit(1)
-	}
-
-	session.InitManager(
+	sessionOpts := []session.Option{
 		session.SetCookieName("TGLY_SID"),
 		session.SetSign([]byte(config.Sessions["key"])),
-		session.SetStore(mgoStore),
-	)
+	}
+
+	// connects to session storage, embedded storage keeps sessions in memory
+	if config.Storage.Driver != storage.DriverEmbedded {
+		mgoStore, err := mongo.NewMongoStore(&mongo.Options{
+			Connection: config.Storage.Connection,
+			DB:         config.Storage.Name,
+			Collection: "sessions",
+			Logger:     log,
+		})
+		if err != nil {
+			log.Errorf(err.Error())
+			os.Exit(1)
+		}
+		sessionOpts = append(sessionOpts, session.SetStore(mgoStore))
+	}
+
+	session.InitManager(sessionOpts...)
 
 	// connects to db storage
-	dbs, err := dbStore.NewMongoStorage(config.Storage.Connection)
+	dbs, err := storage.New(config.Storage)
 	if err != nil {
 		log.Error(err.Error())
 		os.Exit(1)
 	}
-	// set db
-	dbs.WithName(config.Storage.Name)
+
+	// validates bearer tokens instead of trusting owner header
+	var auth *app.JWTAuth
+	if config.Auth != nil && config.Auth.Mode == models.AuthModeJWT {
+		if auth, err = app.NewJWTAuth(config.Auth.JWT); err != nil {
+			log.Error(err.Error())
+			os.Exit(1)
+		}
+	}
 
 	log.Info("API server started")
 
 	app := &app.Toggly{
-		Dbs:    dbs,
-		Ctx:    ctx,
-		Config: config,
-		Logger: log,
+		Storage: dbs,
+		JWT:     auth,
+		Ctx:     ctx,
+		Config:  config,
+		Logger:  log,
 	}
 
 	app.Run()
@@ -0,0 +1,75 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Audit entities enum
+const (
+	AuditEntityProject     = "project"
+	AuditEntityEnvironment = "environment"
+	AuditEntityParameter   = "parameter"
+	AuditEntityPackage     = "package"
+	AuditEntityOverride    = "override"
+)
+
+// Audit actions enum
+const (
+	AuditActionCreate   = "create"
+	AuditActionUpdate   = "update"
+	AuditActionDelete   = "delete"
+	AuditActionRollback = "rollback"
+)
+
+// AuditRecord type, single mutation of an entity
+type AuditRecord struct {
+	ID          primitive.ObjectID      `json:"id" bson:"_id,omitempty"`
+	OwnerID     string                  `json:"owner" bson:"owner_id"`
+	RequestID   string                  `json:"requestId" bson:"request_id"`
+	User        string                  `json:"user,omitempty" bson:"user,omitempty"`
+	Entity      string                  `json:"entity"`
+	Action      string                  `json:"action"`
+	Project     string                  `json:"project,omitempty" bson:"project,omitempty"`
+	Environment string                  `json:"environment,omitempty" bson:"environment,omitempty"`
+	Code        string                  `json:"code"`
+	Instance    string                  `json:"instance,omitempty" bson:"instance,omitempty"`
+	Changes     map[string]*AuditChange `json:"changes,omitempty" bson:"changes,omitempty"`
+	Time        time.Time               `json:"time"`
+}
+
+// AuditChange type, field value before and after mutation
+type AuditChange struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// AuditFilter type, empty fields match everything
+type AuditFilter struct {
+	Entity      string
+	Action      string
+	Project     string
+	Environment string
+	Code        string
+	Instance    string
+	RequestID   string
+	User        string
+	From        time.Time
+	To          time.Time
+	Limit       int64
+}
+
+// Match checks audit record against filter
+func (f *AuditFilter) Match(rec *AuditRecord) bool {
+	return (f.Entity == "" || f.Entity == rec.Entity) &&
+		(f.Action == "" || f.Action == rec.Action) &&
+		(f.Project == "" || f.Project == rec.Project) &&
+		(f.Environment == "" || f.Environment == rec.Environment) &&
+		(f.Code == "" || f.Code == rec.Code) &&
+		(f.Instance == "" || f.Instance == rec.Instance) &&
+		(f.RequestID == "" || f.RequestID == rec.RequestID) &&
+		(f.User == "" || f.User == rec.User) &&
+		(f.From.IsZero() || !rec.Time.Before(f.From)) &&
+		(f.To.IsZero() || rec.Time.Before(f.To))
+}
@@ -0,0 +1,68 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Change request statuses enum
+const (
+	ChangeStatusPending  = "pending"
+	ChangeStatusApproved = "approved"
+	ChangeStatusRejected = "rejected"
+	ChangeStatusApplied  = "applied"
+	ChangeStatusFailed   = "failed"
+)
+
+// Change request actions enum
+const (
+	ChangeActionCreate   = "create"
+	ChangeActionUpdate   = "update"
+	ChangeActionDelete   = "delete"
+	ChangeActionRollback = "rollback"
+	ChangeActionPromote  = "promote"
+)
+
+// Change request entities enum
+const (
+	ChangeEntityParameter = "parameter"
+	ChangeEntityPromotion = "promotion"
+	ChangeEntityOverride  = "override"
+)
+
+// ChangeRequest type, pending change of protected environment,
+// override changes keep object instance id and parameter code,
+// promotions keep reviewed preview which is applied as is,
+// failed change requests keep apply error and are not applied again
+type ChangeRequest struct {
+	ID            primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	ProjectID     primitive.ObjectID `json:"-" bson:"project_id"`
+	EnvironmentID primitive.ObjectID `json:"-" bson:"env_id"`
+	OwnerID       string             `json:"-" bson:"owner_id"`
+	Environment   string             `json:"environment"`
+	Entity        string             `json:"entity"`
+	Action        string             `json:"action"`
+	Code          string             `json:"code,omitempty" bson:"code,omitempty"`
+	Instance      string             `json:"instance,omitempty" bson:"instance,omitempty"`
+	Value         interface{}        `json:"value,omitempty" bson:"value"`
+	Parameter     *Parameter         `json:"parameter,omitempty" bson:"parameter,omitempty"`
+	Version       int64              `json:"version,omitempty" bson:"version,omitempty"`
+	Source        string             `json:"source,omitempty" bson:"source,omitempty"`
+	Promotion     *PromotionRequest  `json:"promotion,omitempty" bson:"promotion,omitempty"`
+	Preview       *Promotion         `json:"preview,omitempty" bson:"preview,omitempty"`
+	Status        string             `json:"status"`
+	Author        string             `json:"author"`
+	Reviewer      string             `json:"reviewer,omitempty" bson:"reviewer,omitempty"`
+	Error         string             `json:"error,omitempty" bson:"error,omitempty"`
+	Comments      []*ChangeComment   `json:"comments,omitempty" bson:"comments,omitempty"`
+	RegDate       time.Time          `json:"reg_date" bson:"reg_date"`
+	UpdDate       time.Time          `json:"upd_date" bson:"upd_date"`
+}
+
+// ChangeComment type
+type ChangeComment struct {
+	Author  string    `json:"author"`
+	Text    string    `json:"text"`
+	RegDate time.Time `json:"reg_date" bson:"reg_date"`
+}
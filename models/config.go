@@ -8,6 +8,34 @@ type Config struct {
 	Storage       *Storage          `yaml:"storage"`
 	Sessions      map[string]string `yaml:"sessions"`
 	MultiUserMode bool              `yaml:"multiUser"`
+	Auth          *Auth             `yaml:"auth"`
+	// AllowedOrigins are browser origins allowed to open WebSocket connections,
+	// same origin only when empty, * allows any
+	AllowedOrigins []string `yaml:"allowedOrigins"`
+}
+
+// Auth modes enum
+const (
+	AuthModeHeader  = "header"
+	AuthModeJWT     = "jwt"
+	AuthModeSession = "session"
+)
+
+// Auth struct, header mode trusts X-Toggly-Owner-Id header
+type Auth struct {
+	Mode string `yaml:"mode"`
+	JWT  *JWT   `yaml:"jwt"`
+}
+
+// JWT struct, HS256 tokens are checked with secret, RS256 ones with keys from JWKS file
+type JWT struct {
+	Algorithm  string `yaml:"algorithm"`
+	Secret     string `yaml:"secret"`
+	JWKS       string `yaml:"jwks"`
+	OwnerClaim string `yaml:"ownerClaim"`
+	UserClaim  string `yaml:"userClaim"`
+	Issuer     string `yaml:"issuer"`
+	Audience   string `yaml:"audience"`
 }
 
 // Storage struct
@@ -35,6 +63,10 @@ const (
 	CtxValueOwner
 	CtxValueEnvID
 	CtxValueAuth
+	CtxValueProject
+	CtxValueRequestID
+	CtxValueUser
+	CtxValueSDKKey
 )
 
 // OwnerFromContext returns context value for project owner
@@ -42,3 +74,47 @@ func OwnerFromContext(r *http.Request) string {
 	owner := r.Context().Value(CtxValueOwner)
 	return owner.(string)
 }
+
+// EnvironmentFromContext returns context value for environment code
+func EnvironmentFromContext(r *http.Request) string {
+	env := r.Context().Value(CtxValueEnvID)
+	return env.(string)
+}
+
+// ProjectFromContext returns context value for project code
+func ProjectFromContext(r *http.Request) string {
+	project := r.Context().Value(CtxValueProject)
+	return project.(string)
+}
+
+// UserFromContext returns context value for acting user,
+// owner acts by itself when user is unknown in single user mode and with SDK keys
+func UserFromContext(r *http.Request) string {
+	if user, ok := r.Context().Value(CtxValueUser).(string); ok && user != "" {
+		return user
+	}
+	return OwnerFromContext(r)
+}
+
+// EmailFromContext returns email of acting user, session user has it in account,
+// other users are expected to be identified by email
+func EmailFromContext(r *http.Request) string {
+	if user, ok := r.Context().Value(CtxValueAuth).(*User); ok {
+		return user.Email
+	}
+	return UserFromContext(r)
+}
+
+// RequestIDFromContext returns context value for request id
+func RequestIDFromContext(r *http.Request) string {
+	if id, ok := r.Context().Value(CtxValueRequestID).(string); ok {
+		return id
+	}
+	return r.Header.Get(XRequestID)
+}
+
+// SDKKeyFromContext returns SDK key request is authorized with, nil when there is no key
+func SDKKeyFromContext(r *http.Request) *SDKKey {
+	key, _ := r.Context().Value(CtxValueSDKKey).(*SDKKey)
+	return key
+}
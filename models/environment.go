@@ -8,10 +8,11 @@ import (
 
 // Environment type
 type Environment struct {
-	ID        primitive.ObjectID `json:"-" bson:"_id"`
-	Code      string             `json:"code"`
-	ProjectID primitive.ObjectID `json:"projectId" bson:"project_id"`
-	OwnerID   primitive.ObjectID `json:"ownerId" bson:"owner_id"`
-	Protected bool               `json:"protected"`
-	RegDate   time.Time          `json:"reg_date" bson:"reg_date"`
+	ID          primitive.ObjectID `json:"-" bson:"_id,omitempty"`
+	Code        string             `json:"code"`
+	Description string             `json:"description"`
+	ProjectID   primitive.ObjectID `json:"-" bson:"project_id"`
+	OwnerID     string             `json:"-" bson:"owner_id"`
+	Protected   bool               `json:"protected"`
+	RegDate     time.Time          `json:"reg_date" bson:"reg_date"`
 }
@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// Event entities enum
+const (
+	EventEntityParameter = "parameter"
+	EventEntityPackage   = "package"
+	EventEntityOverride  = "override"
+)
+
+// Event actions enum
+const (
+	EventActionCreate = "create"
+	EventActionUpdate = "update"
+	EventActionDelete = "delete"
+)
+
+// Event type, configuration change notification,
+// client marks changes of parameters exposed to client SDKs
+type Event struct {
+	ID          uint64    `json:"id"`
+	OwnerID     string    `json:"-"`
+	Project     string    `json:"project"`
+	Environment string    `json:"environment,omitempty"`
+	Entity      string    `json:"entity"`
+	Action      string    `json:"action"`
+	Code        string    `json:"code"`
+	Instance    string    `json:"instance,omitempty"`
+	Client      bool      `json:"-"`
+	Time        time.Time `json:"time"`
+}
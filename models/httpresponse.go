@@ -26,6 +26,17 @@ func JSONResponse(w http.ResponseWriter, r *http.Request, data interface{}) {
 	render.JSON(w, r, data)
 }
 
+// AcceptedResponse creates json body and responds with 202 code
+func AcceptedResponse(w http.ResponseWriter, r *http.Request, data interface{}) {
+	render.Status(r, http.StatusAccepted)
+	JSONResponse(w, r, data)
+}
+
+// NoContentResponse responds with 204 code and empty body
+func NoContentResponse(w http.ResponseWriter, r *http.Request) {
+	render.NoContent(w, r)
+}
+
 // NotFoundResponse creates empty json body and responds with 404 code
 func NotFoundResponse(w http.ResponseWriter, r *http.Request, message string) {
 	ErrorResponseWithStatus(w, r, errors.New(message), http.StatusNotFound)
@@ -58,6 +69,21 @@ func ErrInternalServer(message string) *ErrStatusedResponse {
 	return &ErrStatusedResponse{Message: message, Code: http.StatusInternalServerError}
 }
 
+// ErrNotFound func
+func ErrNotFound(message string) *ErrStatusedResponse {
+	return &ErrStatusedResponse{Message: message, Code: http.StatusNotFound}
+}
+
+// ErrUnauthorized func
+func ErrUnauthorized(message string) *ErrStatusedResponse {
+	return &ErrStatusedResponse{Message: message, Code: http.StatusUnauthorized}
+}
+
+// ErrForbidden func
+func ErrForbidden(message string) *ErrStatusedResponse {
+	return &ErrStatusedResponse{Message: message, Code: http.StatusForbidden}
+}
+
 // ErrConflict func
 func ErrConflict(message string) *ErrStatusedResponse {
 	return &ErrStatusedResponse{Message: message, Code: http.StatusConflict}
@@ -0,0 +1,54 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Invitation statuses enum
+const (
+	InvitationStatusPending  = "pending"
+	InvitationStatusAccepted = "accepted"
+	InvitationStatusDeclined = "declined"
+	InvitationStatusRevoked  = "revoked"
+)
+
+// Invitation type, invitation either to organization or to project,
+// only token hash is stored, plain token is returned once on creation
+type Invitation struct {
+	ID           primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	OwnerID      string             `json:"-" bson:"owner_id"`
+	ProjectID    primitive.ObjectID `json:"-" bson:"project_id"`
+	Organization string             `json:"organization,omitempty" bson:"organization,omitempty"`
+	Project      string             `json:"project,omitempty" bson:"project,omitempty"`
+	Email        string             `json:"email"`
+	Role         string             `json:"role"`
+	Environments []string           `json:"environments,omitempty" bson:"environments,omitempty"`
+	Hash         string             `json:"-"`
+	Token        string             `json:"token,omitempty" bson:"-"`
+	Status       string             `json:"status"`
+	InvitedBy    string             `json:"invited_by" bson:"invited_by"`
+	AcceptedBy   string             `json:"accepted_by,omitempty" bson:"accepted_by,omitempty"`
+	ExpiresAt    time.Time          `json:"expires_at" bson:"expires_at"`
+	RegDate      time.Time          `json:"reg_date" bson:"reg_date"`
+	UpdDate      time.Time          `json:"upd_date" bson:"upd_date"`
+}
+
+// IsExpired checks that invitation can't be accepted anymore
+func (i *Invitation) IsExpired(now time.Time) bool {
+	return !now.Before(i.ExpiresAt)
+}
+
+// InvitationRequest type, ttl is a duration like 72h
+type InvitationRequest struct {
+	Email        string   `json:"email"`
+	Role         string   `json:"role"`
+	Environments []string `json:"environments,omitempty"`
+	TTL          string   `json:"ttl,omitempty"`
+}
+
+// InvitationToken type, token from invitation link
+type InvitationToken struct {
+	Token string `json:"token"`
+}
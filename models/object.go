@@ -1,13 +1,69 @@
 package models
 
-import "go.mongodb.org/mongo-driver/bson/primitive"
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
 
 // Object struct
 type Object struct {
-	ID         primitive.ObjectID     `json:"-" bson:"_id"`
+	ID         primitive.ObjectID     `json:"-" bson:"_id,omitempty"`
 	InstanceID string                 `json:"instanceId" bson:"instance_id"`
 	Name       string                 `json:"name"`
 	Props      map[string]interface{} `json:"props"`
-	ProjectID  primitive.ObjectID     `json:"projectId" bson:"project_id"`
-	OwnerID    primitive.ObjectID     `json:"ownerId" bson:"owner_id"`
+	Overrides  []*Override            `json:"overrides,omitempty" bson:"overrides,omitempty"`
+	ProjectID  primitive.ObjectID     `json:"-" bson:"project_id"`
+	OwnerID    string                 `json:"-" bson:"owner_id"`
+	RegDate    time.Time              `json:"reg_date" bson:"reg_date"`
+}
+
+// Override struct, object specific parameter value for environment
+type Override struct {
+	Environment string      `json:"environment"`
+	Parameter   string      `json:"parameter"`
+	Value       interface{} `json:"value"`
+}
+
+// Override returns object override for environment parameter
+func (o *Object) Override(env string, param string) *Override {
+	for _, ovr := range o.Overrides {
+		if ovr.Environment == env && ovr.Parameter == param {
+			return ovr
+		}
+	}
+	return nil
+}
+
+// SetOverride adds or replaces object override for environment parameter
+func (o *Object) SetOverride(env string, param string, value interface{}) {
+	if ovr := o.Override(env, param); ovr != nil {
+		ovr.Value = value
+		return
+	}
+	o.Overrides = append(o.Overrides, &Override{Environment: env, Parameter: param, Value: value})
+}
+
+// RemoveOverrides removes all object overrides of environment
+func (o *Object) RemoveOverrides(env string) bool {
+	overrides := make([]*Override, 0, len(o.Overrides))
+	for _, ovr := range o.Overrides {
+		if ovr.Environment != env {
+			overrides = append(overrides, ovr)
+		}
+	}
+	removed := len(overrides) != len(o.Overrides)
+	o.Overrides = overrides
+	return removed
+}
+
+// RemoveOverride removes object override for environment parameter
+func (o *Object) RemoveOverride(env string, param string) bool {
+	for i, ovr := range o.Overrides {
+		if ovr.Environment == env && ovr.Parameter == param {
+			o.Overrides = append(o.Overrides[:i], o.Overrides[i+1:]...)
+			return true
+		}
+	}
+	return false
 }
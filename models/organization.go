@@ -0,0 +1,96 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Organization roles enum, owners and admins have owner access to every organization project
+const (
+	OrgRoleOwner  = "owner"
+	OrgRoleAdmin  = "admin"
+	OrgRoleMember = "member"
+)
+
+// Organization type, organization id is the owner of its projects
+type Organization struct {
+	ID          primitive.ObjectID    `json:"id" bson:"_id,omitempty"`
+	Code        string                `json:"code"`
+	Name        string                `json:"name"`
+	Description string                `json:"description"`
+	Members     []*OrganizationMember `json:"members" bson:"members"`
+	Teams       []*Team               `json:"teams" bson:"teams"`
+	RegDate     time.Time             `json:"reg_date" bson:"reg_date"`
+}
+
+// OrganizationMember type
+type OrganizationMember struct {
+	UserID string `json:"user_id" bson:"user_id"`
+	Role   string `json:"role"`
+}
+
+// Team type, project roles granted to team apply to all its members
+type Team struct {
+	Code    string   `json:"code"`
+	Name    string   `json:"name"`
+	Members []string `json:"members"`
+}
+
+// IsOrgRole checks that organization role is known
+func IsOrgRole(role string) bool {
+	return role == OrgRoleOwner || role == OrgRoleAdmin || role == OrgRoleMember
+}
+
+// Member returns organization member by user id, nil when user is not a member
+func (o *Organization) Member(user string) *OrganizationMember {
+	for _, member := range o.Members {
+		if member.UserID == user {
+			return member
+		}
+	}
+	return nil
+}
+
+// IsAdmin checks that user manages organization
+func (o *Organization) IsAdmin(user string) bool {
+	member := o.Member(user)
+	return member != nil && (member.Role == OrgRoleOwner || member.Role == OrgRoleAdmin)
+}
+
+// IsOwner checks that user owns organization
+func (o *Organization) IsOwner(user string) bool {
+	member := o.Member(user)
+	return member != nil && member.Role == OrgRoleOwner
+}
+
+// Team returns team by code, nil when there is no such team
+func (o *Organization) Team(code string) *Team {
+	for _, team := range o.Teams {
+		if team.Code == code {
+			return team
+		}
+	}
+	return nil
+}
+
+// TeamsOf returns teams user belongs to
+func (o *Organization) TeamsOf(user string) []*Team {
+	results := make([]*Team, 0)
+	for _, team := range o.Teams {
+		if team.HasMember(user) {
+			results = append(results, team)
+		}
+	}
+	return results
+}
+
+// HasMember checks that user is in team
+func (t *Team) HasMember(user string) bool {
+	for _, member := range t.Members {
+		if member == user {
+			return true
+		}
+	}
+	return false
+}
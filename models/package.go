@@ -1,12 +1,18 @@
 package models
 
-import "go.mongodb.org/mongo-driver/bson/primitive"
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
 
 // Package struct
 type Package struct {
-	ID        primitive.ObjectID `json:"-" bson:"_id"`
-	Code      string             `json:"code"`
-	Name      string             `json:"name"`
-	ProjectID primitive.ObjectID `json:"projectId" bson:"project_id"`
-	OwnerID   primitive.ObjectID `json:"ownerId" bson:"owner_id"`
+	ID          primitive.ObjectID `json:"-" bson:"_id,omitempty"`
+	Code        string             `json:"code"`
+	Name        string             `json:"name"`
+	Description string             `json:"description"`
+	ProjectID   primitive.ObjectID `json:"-" bson:"project_id"`
+	OwnerID     string             `json:"-" bson:"owner_id"`
+	RegDate     time.Time          `json:"reg_date" bson:"reg_date"`
 }
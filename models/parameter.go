@@ -1,5 +1,13 @@
 package models
 
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
 // Parameter types enum
 const (
 	ParameterTypeBool   = "bool"
@@ -9,12 +17,130 @@ const (
 
 // Parameter type
 type Parameter struct {
-	Code string `json:"code"`
-	// Project       string        `json:"project"`
-	// Environment   string        `json:"environment"`
-	// Group         string        `json:"group"`
-	Description   string        `json:"description"`
-	Type          string        `json:"type"`
-	Value         interface{}   `json:"value"`
-	AllowedValues []interface{} `json:"allowed_values,omitempty" bson:"allowed_values,omitempty"`
+	ID            primitive.ObjectID `json:"-" bson:"_id,omitempty"`
+	Code          string             `json:"code"`
+	ProjectID     primitive.ObjectID `json:"-" bson:"project_id"`
+	EnvironmentID primitive.ObjectID `json:"-" bson:"env_id"`
+	OwnerID       string             `json:"-" bson:"owner_id"`
+	Package       string             `json:"package,omitempty" bson:"package,omitempty"`
+	Description   string             `json:"description"`
+	Type          string             `json:"type"`
+	Value         interface{}        `json:"value"`
+	AllowedValues []interface{}      `json:"allowed_values,omitempty" bson:"allowed_values,omitempty"`
+	Rules         []*Rule            `json:"rules,omitempty" bson:"rules,omitempty"`
+	Rollout       *Rollout           `json:"rollout,omitempty" bson:"rollout,omitempty"`
+	Client        bool               `json:"client,omitempty" bson:"client,omitempty"`
+	RegDate       time.Time          `json:"reg_date" bson:"reg_date"`
+}
+
+// Validate checks parameter type, value, allowed values, rules and rollout and normalizes them
+func (p *Parameter) Validate() error {
+	allowed := make([]interface{}, 0, len(p.AllowedValues))
+	for _, v := range p.AllowedValues {
+		val, err := p.CastValue(v)
+		if err != nil {
+			return err
+		}
+		allowed = append(allowed, val)
+	}
+	if len(allowed) > 0 {
+		p.AllowedValues = allowed
+	}
+	val, err := p.CheckValue(p.Value)
+	if err != nil {
+		return err
+	}
+	p.Value = val
+	for _, rule := range p.Rules {
+		if err := rule.Validate(); err != nil {
+			return err
+		}
+		val, err := p.CheckValue(rule.Value)
+		if err != nil {
+			return err
+		}
+		rule.Value = val
+	}
+	if p.Rollout != nil {
+		if err := p.Rollout.Validate(); err != nil {
+			return err
+		}
+		for _, v := range p.Rollout.Variations {
+			val, err := p.CheckValue(v.Value)
+			if err != nil {
+				return err
+			}
+			v.Value = val
+		}
+	}
+	return nil
+}
+
+// Evaluate returns value of the first matching rule,
+// rollout variation or parameter value
+func (p *Parameter) Evaluate(attrs map[string]interface{}) interface{} {
+	for _, rule := range p.Rules {
+		if !rule.Match(attrs) {
+			continue
+		}
+		// skip rules which don't fit parameter anymore
+		if val, err := p.CheckValue(rule.Value); err == nil {
+			return val
+		}
+	}
+	if p.Rollout != nil {
+		if v := p.Rollout.Variation(p.Code, attrs); v != nil {
+			if val, err := p.CheckValue(v.Value); err == nil {
+				return val
+			}
+		}
+	}
+	return p.Value
+}
+
+// CheckValue casts value to parameter type and verifies it against allowed values
+func (p *Parameter) CheckValue(v interface{}) (interface{}, error) {
+	val, err := p.CastValue(v)
+	if err != nil {
+		return nil, err
+	}
+	if len(p.AllowedValues) == 0 {
+		return val, nil
+	}
+	for _, a := range p.AllowedValues {
+		if allowed, err := p.CastValue(a); err == nil && allowed == val {
+			return val, nil
+		}
+	}
+	return nil, ErrBadRequest(fmt.Sprintf("Value [%v] is not allowed", v))
+}
+
+// CastValue converts value to parameter type
+func (p *Parameter) CastValue(v interface{}) (interface{}, error) {
+	switch p.Type {
+	case ParameterTypeBool:
+		if val, ok := v.(bool); ok {
+			return val, nil
+		}
+	case ParameterTypeString:
+		if val, ok := v.(string); ok {
+			return val, nil
+		}
+	case ParameterTypeInt:
+		switch val := v.(type) {
+		case int:
+			return int64(val), nil
+		case int32:
+			return int64(val), nil
+		case int64:
+			return val, nil
+		case float64:
+			if val == math.Trunc(val) {
+				return int64(val), nil
+			}
+		}
+	default:
+		return nil, ErrBadRequest(fmt.Sprintf("Type [%s] is invalid", p.Type))
+	}
+	return nil, ErrBadRequest(fmt.Sprintf("Value [%v] doesn't match type [%s]", v, p.Type))
 }
@@ -1,24 +1,77 @@
 package models
 
 import (
+	"fmt"
 	"time"
 
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// ProjectStatus type
+type ProjectStatus string
+
 // ProjectStatus enum
 const (
-	ProjectStatusActive   = "active"
-	ProjectStatusDisabled = "disabled"
+	ProjectStatusActive   ProjectStatus = "active"
+	ProjectStatusDisabled ProjectStatus = "disabled"
 )
 
+// UnmarshalBSONValue decodes status, numeric statuses were stored before archival existed
+// so all of them mean active project
+func (s *ProjectStatus) UnmarshalBSONValue(t bsontype.Type, data []byte) error {
+	switch t {
+	case bsontype.String:
+		var value string
+		if err := (bson.RawValue{Type: t, Value: data}).Unmarshal(&value); err != nil {
+			return err
+		}
+		*s = ProjectStatus(value)
+	case bsontype.Int32, bsontype.Int64, bsontype.Double, bsontype.Null:
+		*s = ProjectStatusActive
+	default:
+		return fmt.Errorf("Project status of type %s is invalid", t)
+	}
+	return nil
+}
+
 // Project type
 type Project struct {
 	ID          primitive.ObjectID `json:"-" bson:"_id,omitempty"`
 	Code        string             `json:"code"`
 	Name        string             `json:"name"`
 	OwnerID     string             `json:"-" bson:"owner_id"`
-	Status      int                `json:"status"`
+	Status      ProjectStatus      `json:"status"`
 	Description string             `json:"description"`
+	Members     []*ProjectMember   `json:"members" bson:"members"`
 	RegDate     time.Time          `json:"reg_date" bson:"reg_date"`
 }
+
+// Member returns project member by user id, nil when user is not a member
+func (p *Project) Member(user string) *ProjectMember {
+	for _, member := range p.Members {
+		if member.Team == "" && member.UserID == user {
+			return member
+		}
+	}
+	return nil
+}
+
+// TeamMember returns role granted to team, nil when team is not a member
+func (p *Project) TeamMember(team string) *ProjectMember {
+	for _, member := range p.Members {
+		if member.Team != "" && member.Team == team {
+			return member
+		}
+	}
+	return nil
+}
+
+// Grant returns existing role of the same user or team as given member
+func (p *Project) Grant(member ProjectMember) *ProjectMember {
+	if member.Team != "" {
+		return p.TeamMember(member.Team)
+	}
+	return p.Member(member.UserID)
+}
@@ -0,0 +1,71 @@
+package models
+
+import "fmt"
+
+// Promotion item actions enum
+const (
+	PromotionActionCreate = "create"
+	PromotionActionUpdate = "update"
+	PromotionActionDelete = "delete"
+)
+
+// Promotion type, parameter differences between source and target environments
+type Promotion struct {
+	Source    string           `json:"source"`
+	Target    string           `json:"target"`
+	Protected bool             `json:"protected"`
+	Items     []*PromotionItem `json:"items"`
+}
+
+// PromotionItem type, change of single parameter in target environment,
+// parameter snapshots are stored with change requests
+type PromotionItem struct {
+	Code    string                  `json:"code"`
+	Action  string                  `json:"action"`
+	Changes map[string]*AuditChange `json:"changes"`
+	Before  *Parameter              `json:"-"`
+	After   *Parameter              `json:"-"`
+}
+
+// HasItem checks that parameter has changes to promote
+func (p *Promotion) HasItem(code string) bool {
+	for _, item := range p.Items {
+		if item.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+// Select returns promotion of requested items only
+func (p *Promotion) Select(req PromotionRequest) (*Promotion, error) {
+	selected := make(map[string]bool)
+	for _, code := range req.Parameters {
+		selected[code] = true
+	}
+	for code := range selected {
+		if !p.HasItem(code) {
+			return nil, ErrBadRequest(fmt.Sprintf("Parameter [%s] has no changes to promote", code))
+		}
+	}
+	result := &Promotion{
+		Source:    p.Source,
+		Target:    p.Target,
+		Protected: p.Protected,
+		Items:     make([]*PromotionItem, 0),
+	}
+	for _, item := range p.Items {
+		if (len(selected) == 0 && item.Action == PromotionActionDelete) || (len(selected) != 0 && !selected[item.Code]) {
+			continue
+		}
+		result.Items = append(result.Items, item)
+	}
+	return result, nil
+}
+
+// PromotionRequest type, empty parameters list applies every create and update,
+// deletions are applied only when listed explicitly
+type PromotionRequest struct {
+	Parameters []string `json:"parameters,omitempty"`
+	Confirm    bool     `json:"confirm"`
+}
@@ -0,0 +1,90 @@
+package models
+
+// Project roles enum
+const (
+	RoleOwner  = "owner"
+	RoleAdmin  = "admin"
+	RoleEditor = "editor"
+	RoleViewer = "viewer"
+)
+
+// Permissions enum, every role has permissions of lower roles
+const (
+	PermissionRead   = "read"
+	PermissionWrite  = "write"
+	PermissionManage = "manage"
+	PermissionOwn    = "own"
+)
+
+var roleRanks = map[string]int{RoleViewer: 1, RoleEditor: 2, RoleAdmin: 3, RoleOwner: 4}
+
+var permissionRanks = map[string]int{PermissionRead: 1, PermissionWrite: 2, PermissionManage: 3, PermissionOwn: 4}
+
+// ProjectMember type, role is granted either to user or to organization team,
+// editor with environments can change those environments only
+type ProjectMember struct {
+	UserID       string   `json:"user_id,omitempty" bson:"user_id,omitempty"`
+	Team         string   `json:"team,omitempty" bson:"team,omitempty"`
+	Role         string   `json:"role"`
+	Environments []string `json:"environments,omitempty" bson:"environments,omitempty"`
+}
+
+// IsRole checks that role is known
+func IsRole(role string) bool {
+	_, ok := roleRanks[role]
+	return ok
+}
+
+// Allows checks that member role grants permission
+func (m *ProjectMember) Allows(perm string) bool {
+	return roleRanks[m.Role] >= permissionRanks[perm]
+}
+
+// Outranks checks that member grants more than other one, nil grants nothing
+func (m *ProjectMember) Outranks(other *ProjectMember) bool {
+	if other == nil {
+		return true
+	}
+	if roleRanks[m.Role] != roleRanks[other.Role] {
+		return roleRanks[m.Role] > roleRanks[other.Role]
+	}
+	return !m.IsScoped() && other.IsScoped()
+}
+
+// IsScoped checks that member is limited to some environments
+func (m *ProjectMember) IsScoped() bool {
+	return m.Role == RoleEditor && len(m.Environments) > 0
+}
+
+// RemoveEnvironment takes environment out of editor scope, editor scoped
+// to that environment only becomes viewer instead of unscoped editor
+func (m *ProjectMember) RemoveEnvironment(env string) bool {
+	if !m.IsScoped() || !m.HasEnvironment(env) {
+		return false
+	}
+	envs := make([]string, 0, len(m.Environments))
+	for _, code := range m.Environments {
+		if code != env {
+			envs = append(envs, code)
+		}
+	}
+	if len(envs) == 0 {
+		m.Role = RoleViewer
+		envs = nil
+	}
+	m.Environments = envs
+	return true
+}
+
+// HasEnvironment checks that member may change environment
+func (m *ProjectMember) HasEnvironment(env string) bool {
+	if !m.IsScoped() {
+		return true
+	}
+	for _, code := range m.Environments {
+		if code == env {
+			return true
+		}
+	}
+	return false
+}
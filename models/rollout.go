@@ -0,0 +1,74 @@
+package models
+
+import (
+	"crypto/sha1"
+	"encoding/binary"
+	"fmt"
+)
+
+// RolloutBuckets is a number of buckets rollout splits contexts into, 0.01% each
+const RolloutBuckets = 10000
+
+// Rollout type, splits contexts between variations by stable hash of attribute
+type Rollout struct {
+	// Attribute is a context attribute used for bucketing, instanceId by default
+	Attribute string `json:"attribute,omitempty" bson:"attribute,omitempty"`
+	// Salt is mixed into hash, parameter code by default
+	Salt       string       `json:"salt,omitempty" bson:"salt,omitempty"`
+	Variations []*Variation `json:"variations"`
+}
+
+// Variation type, weight is a percentage of contexts served with value
+type Variation struct {
+	Weight float64     `json:"weight"`
+	Value  interface{} `json:"value"`
+}
+
+// Validate checks variation weights, they must not exceed 100% in total
+func (r *Rollout) Validate() error {
+	total := 0.0
+	for _, v := range r.Variations {
+		if v == nil {
+			return ErrBadRequest("Variation is empty")
+		}
+		if v.Weight < 0 {
+			return ErrBadRequest("Variation weight must be positive")
+		}
+		total += v.Weight
+	}
+	if total > 100 {
+		return ErrBadRequest("Variation weights exceed 100%")
+	}
+	return nil
+}
+
+// Variation returns variation for context attributes,
+// nil when attribute is missed or bucket is out of variations
+func (r *Rollout) Variation(salt string, attrs map[string]interface{}) *Variation {
+	attribute := r.Attribute
+	if attribute == "" {
+		attribute = "instanceId"
+	}
+	attr, ok := attrs[attribute]
+	if !ok {
+		return nil
+	}
+	if r.Salt != "" {
+		salt = r.Salt
+	}
+	bucket := Bucket(salt, fmt.Sprint(attr))
+	upper := 0.0
+	for _, v := range r.Variations {
+		upper += v.Weight * RolloutBuckets / 100
+		if float64(bucket) < upper {
+			return v
+		}
+	}
+	return nil
+}
+
+// Bucket returns stable bucket of key for salt
+func Bucket(salt string, key string) uint32 {
+	sum := sha1.Sum([]byte(salt + "." + key))
+	return binary.BigEndian.Uint32(sum[:4]) % RolloutBuckets
+}
@@ -0,0 +1,176 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Rule operators enum
+const (
+	RuleOperatorEquals      = "equals"
+	RuleOperatorNotEquals   = "not_equals"
+	RuleOperatorIn          = "in"
+	RuleOperatorContains    = "contains"
+	RuleOperatorRegex       = "regex"
+	RuleOperatorGreaterThan = "greater_than"
+	RuleOperatorLessThan    = "less_than"
+)
+
+// Rule type, targeting rule serves value when context attribute matches operand
+type Rule struct {
+	Attribute string      `json:"attribute"`
+	Operator  string      `json:"operator"`
+	Operand   interface{} `json:"operand"`
+	Value     interface{} `json:"value"`
+}
+
+// Validate checks rule operator and operand
+func (r *Rule) Validate() error {
+	if r == nil {
+		return ErrBadRequest("Rule is empty")
+	}
+	if r.Attribute == "" {
+		return ErrBadRequest("Rule attribute is invalid")
+	}
+	switch r.Operator {
+	case RuleOperatorEquals, RuleOperatorNotEquals, RuleOperatorContains:
+	case RuleOperatorIn:
+		if _, ok := toList(r.Operand); !ok {
+			return ErrBadRequest("Rule operand of [in] operator must be a list")
+		}
+	case RuleOperatorRegex:
+		if _, err := compileRegex(fmt.Sprint(r.Operand)); err != nil {
+			return ErrBadRequest(fmt.Sprintf("Rule regex is invalid: %s", err.Error()))
+		}
+	case RuleOperatorGreaterThan, RuleOperatorLessThan:
+		if _, ok := toFloat(r.Operand); !ok {
+			return ErrBadRequest(fmt.Sprintf("Rule operand of [%s] operator must be a number", r.Operator))
+		}
+	default:
+		return ErrBadRequest(fmt.Sprintf("Rule operator [%s] is invalid", r.Operator))
+	}
+	return nil
+}
+
+// Match checks rule against context attributes
+func (r *Rule) Match(attrs map[string]interface{}) bool {
+	attr, ok := attrs[r.Attribute]
+	if !ok {
+		return false
+	}
+	switch r.Operator {
+	case RuleOperatorEquals:
+		return equals(attr, r.Operand)
+	case RuleOperatorNotEquals:
+		return !equals(attr, r.Operand)
+	case RuleOperatorIn:
+		if list, ok := toList(r.Operand); ok {
+			for _, item := range list {
+				if equals(attr, item) {
+					return true
+				}
+			}
+		}
+	case RuleOperatorContains:
+		if list, ok := toList(attr); ok {
+			for _, item := range list {
+				if equals(item, r.Operand) {
+					return true
+				}
+			}
+			return false
+		}
+		return strings.Contains(fmt.Sprint(attr), fmt.Sprint(r.Operand))
+	case RuleOperatorRegex:
+		re, err := compileRegex(fmt.Sprint(r.Operand))
+		return err == nil && re.MatchString(fmt.Sprint(attr))
+	case RuleOperatorGreaterThan, RuleOperatorLessThan:
+		a, ok := toFloat(attr)
+		if !ok {
+			return false
+		}
+		b, ok := toFloat(r.Operand)
+		if !ok {
+			return false
+		}
+		if r.Operator == RuleOperatorGreaterThan {
+			return a > b
+		}
+		return a < b
+	}
+	return false
+}
+
+// regexCacheSize limits number of compiled patterns kept in cache
+const regexCacheSize = 1000
+
+// regexCache keeps compiled rule patterns, rules are loaded from storage
+// for every evaluation so patterns are cached by their text
+var regexCache = struct {
+	sync.RWMutex
+	items map[string]*regexp.Regexp
+}{items: make(map[string]*regexp.Regexp)}
+
+// compileRegex returns cached pattern, cache is cleared when it is full
+func compileRegex(pattern string) (*regexp.Regexp, error) {
+	regexCache.RLock()
+	re, ok := regexCache.items[pattern]
+	regexCache.RUnlock()
+	if ok {
+		return re, nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	regexCache.Lock()
+	if len(regexCache.items) >= regexCacheSize {
+		regexCache.items = make(map[string]*regexp.Regexp)
+	}
+	regexCache.items[pattern] = re
+	regexCache.Unlock()
+	return re, nil
+}
+
+// equals compares values loosely, context attributes may come as strings
+func equals(a interface{}, b interface{}) bool {
+	if x, ok := toFloat(a); ok {
+		if y, ok := toFloat(b); ok {
+			return x == y
+		}
+	}
+	return fmt.Sprint(a) == fmt.Sprint(b)
+}
+
+// toList accepts JSON lists and lists loaded from storage
+func toList(v interface{}) ([]interface{}, bool) {
+	switch val := v.(type) {
+	case []interface{}:
+		return val, true
+	case primitive.A:
+		return []interface{}(val), true
+	}
+	return nil, false
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch val := v.(type) {
+	case int:
+		return float64(val), true
+	case int32:
+		return float64(val), true
+	case int64:
+		return float64(val), true
+	case float64:
+		return val, true
+	case string:
+		f, err := strconv.ParseFloat(val, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
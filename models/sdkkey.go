@@ -0,0 +1,49 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// SDK key kinds enum
+const (
+	SDKKeyServer = "server"
+	SDKKeyClient = "client"
+)
+
+// SDKKey type, only key hash is stored, plain key is returned once on creation
+type SDKKey struct {
+	ID            primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	ProjectID     primitive.ObjectID `json:"-" bson:"project_id"`
+	EnvironmentID primitive.ObjectID `json:"-" bson:"env_id"`
+	OwnerID       string             `json:"-" bson:"owner_id"`
+	Project       string             `json:"project"`
+	Environment   string             `json:"environment"`
+	Kind          string             `json:"kind"`
+	Hash          string             `json:"-"`
+	Prefix        string             `json:"prefix"`
+	Key           string             `json:"key,omitempty" bson:"-"`
+	ExpiresAt     *time.Time         `json:"expires_at,omitempty" bson:"expires_at,omitempty"`
+	RegDate       time.Time          `json:"reg_date" bson:"reg_date"`
+}
+
+// IsExpired checks that key grace period is over
+func (k *SDKKey) IsExpired(now time.Time) bool {
+	return k.ExpiresAt != nil && !now.Before(*k.ExpiresAt)
+}
+
+// Receives checks that key holder is notified of event,
+// client keys receive changes of client parameters only
+func (k *SDKKey) Receives(evt *Event) bool {
+	if k == nil || k.Kind != SDKKeyClient {
+		return true
+	}
+	return evt.Entity == EventEntityParameter && evt.Client
+}
+
+// SDKKeyRequest type, grace is a duration like 24h
+type SDKKeyRequest struct {
+	Kind  string `json:"kind"`
+	Grace string `json:"grace,omitempty"`
+}
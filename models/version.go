@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Parameter version actions enum
+const (
+	VersionActionCreate   = "create"
+	VersionActionUpdate   = "update"
+	VersionActionDelete   = "delete"
+	VersionActionRollback = "rollback"
+)
+
+// ParameterVersion type, immutable snapshot of parameter in environment,
+// deleted parameters are stored as versions without snapshot
+type ParameterVersion struct {
+	ID            primitive.ObjectID `json:"-" bson:"_id,omitempty"`
+	ProjectID     primitive.ObjectID `json:"-" bson:"project_id"`
+	EnvironmentID primitive.ObjectID `json:"-" bson:"env_id"`
+	OwnerID       string             `json:"-" bson:"owner_id"`
+	Code          string             `json:"code"`
+	Version       int64              `json:"version"`
+	Action        string             `json:"action"`
+	RollbackOf    int64              `json:"rollback_of,omitempty" bson:"rollback_of,omitempty"`
+	Parameter     *Parameter         `json:"parameter,omitempty" bson:"parameter,omitempty"`
+	Time          time.Time          `json:"time"`
+}
+
+// ParameterVersionDiff type, changes between two parameter versions
+type ParameterVersionDiff struct {
+	Code    string                  `json:"code"`
+	From    int64                   `json:"from"`
+	To      int64                   `json:"to"`
+	Changes map[string]*AuditChange `json:"changes"`
+}
@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"bitbucket.org/toggly/toggly-server/models"
+	"bitbucket.org/toggly/toggly-server/storage"
+	"github.com/op/go-logging"
+)
+
+// Access Service checks project roles, owner has every permission in its projects,
+// so do owners and admins of organization owning projects
+type Access struct {
+	Storage storage.Storage
+	Ctx     context.Context
+	Config  *models.Config
+	Logger  *logging.Logger
+}
+
+// Check verifies that user has permission in project,
+// editors are checked against environment for write permission
+func (a *Access) Check(owner string, user string, project string, env string, perm string) error {
+	if a.isOwner(owner, user) {
+		return nil
+	}
+	if project == "" {
+		return models.ErrForbidden("Only owner has access")
+	}
+	// missing project is reported by endpoint itself
+	if !a.Storage.ProjectCRUD().IsExist(owner, project) {
+		return nil
+	}
+	item := a.Storage.ProjectCRUD().Get(owner, project)
+
+	member := a.member(owner, item, user)
+	if member == nil {
+		return models.ErrNotFound(fmt.Sprintf("Project with code [%s] is not found", project))
+	}
+	if !member.Allows(perm) {
+		return models.ErrForbidden(fmt.Sprintf("Role [%s] has no %s permission", member.Role, perm))
+	}
+	if perm != models.PermissionWrite || member.Role != models.RoleEditor {
+		return nil
+	}
+
+	if env == "" {
+		if member.IsScoped() {
+			return models.ErrForbidden("Editor is limited to environments")
+		}
+		return nil
+	}
+	if !member.HasEnvironment(env) {
+		return models.ErrForbidden(fmt.Sprintf("Editor has no access to environment [%s]", env))
+	}
+	if a.Storage.EnvironmentCRUD().Get(owner, item.ID, env).Protected {
+		return models.ErrForbidden(fmt.Sprintf("Environment [%s] is protected", env))
+	}
+	return nil
+}
+
+// Role returns user role in project, empty when user is not a member
+func (a *Access) Role(owner string, user string, project string) string {
+	if a.isOwner(owner, user) {
+		return models.RoleOwner
+	}
+	if member := a.Storage.ProjectCRUD().Get(owner, project).Member(user); member != nil {
+		return member.Role
+	}
+	return ""
+}
+
+// Projects returns projects user is a member of
+func (a *Access) Projects(owner string, user string) []*models.Project {
+	recs := a.Storage.ProjectCRUD().List(owner)
+	if a.isOwner(owner, user) {
+		return recs
+	}
+	results := make([]*models.Project, 0)
+	for _, rec := range recs {
+		if a.member(owner, rec, user) != nil {
+			results = append(results, rec)
+		}
+	}
+	return results
+}
+
+func (a *Access) isOwner(owner string, user string) bool {
+	if user == owner {
+		return true
+	}
+	org := ownerOrganization(a.Storage, owner)
+	return org != nil && org.IsAdmin(user)
+}
+
+// member returns the strongest role user has in project, directly or through organization teams
+func (a *Access) member(owner string, item *models.Project, user string) *models.ProjectMember {
+	result := item.Member(user)
+	org := ownerOrganization(a.Storage, owner)
+	if org == nil {
+		return result
+	}
+	for _, team := range org.TeamsOf(user) {
+		if grant := item.TeamMember(team.Code); grant != nil && grant.Outranks(result) {
+			result = grant
+		}
+	}
+	return result
+}
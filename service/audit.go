@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"time"
+
+	"bitbucket.org/toggly/toggly-server/models"
+	"bitbucket.org/toggly/toggly-server/storage"
+	"github.com/op/go-logging"
+)
+
+// Audit Service
+type Audit struct {
+	Storage storage.Storage
+	Ctx     context.Context
+	Config  *models.Config
+	Logger  *logging.Logger
+}
+
+// List audit records matching filter, newest first
+func (a *Audit) List(owner string, filter *models.AuditFilter) []*models.AuditRecord {
+	return a.Storage.AuditCRUD().List(owner, filter)
+}
+
+// Record stores audit record with before/after diff of entity,
+// audit failures are logged and never break the mutation itself
+func (a *Audit) Record(rec *models.AuditRecord, before interface{}, after interface{}) {
+	changes, err := diff(before, after)
+	if err != nil {
+		a.Logger.Errorf("Audit.Record: %s", err.Error())
+		return
+	}
+	rec.Changes = changes
+	rec.Time = time.Now()
+
+	if _, err := a.Storage.AuditCRUD().Create(rec); err != nil {
+		a.Logger.Errorf("Audit.Record: %s", err.Error())
+	}
+}
+
+// diff compares JSON representations of entities field by field,
+// nil entity stands for absent one (create or delete)
+func diff(before interface{}, after interface{}) (map[string]*models.AuditChange, error) {
+	from, err := fields(before)
+	if err != nil {
+		return nil, err
+	}
+	to, err := fields(after)
+	if err != nil {
+		return nil, err
+	}
+	changes := make(map[string]*models.AuditChange)
+	for key, value := range from {
+		if !reflect.DeepEqual(value, to[key]) {
+			changes[key] = &models.AuditChange{Before: value, After: to[key]}
+		}
+	}
+	for key, value := range to {
+		if _, ok := from[key]; !ok {
+			changes[key] = &models.AuditChange{After: value}
+		}
+	}
+	return changes, nil
+}
+
+func fields(entity interface{}) (map[string]interface{}, error) {
+	result := make(map[string]interface{})
+	if entity == nil || reflect.ValueOf(entity).IsNil() {
+		return result, nil
+	}
+	data, err := json.Marshal(entity)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
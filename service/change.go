@@ -0,0 +1,329 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"bitbucket.org/toggly/toggly-server/models"
+	"bitbucket.org/toggly/toggly-server/storage"
+	"github.com/op/go-logging"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Change Service, changes of protected environments go through approval
+type Change struct {
+	Storage storage.Storage
+	Events  *Events
+	Ctx     context.Context
+	Config  *models.Config
+	Logger  *logging.Logger
+}
+
+// IsRequired checks that environment changes must be approved
+func (a *Change) IsRequired(owner string, project string, env string) bool {
+	environment, err := findEnvironment(a.Storage, owner, project, env)
+	return err == nil && environment.Protected
+}
+
+// List project change requests, filtered by environment and status when given
+func (a *Change) List(owner string, project string, env string, status string) ([]*models.ChangeRequest, error) {
+	proj, err := findProject(a.Storage, owner, project)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]*models.ChangeRequest, 0)
+	for _, rec := range a.Storage.ChangeCRUD().List(owner, proj.ID) {
+		if (env == "" || rec.Environment == env) && (status == "" || rec.Status == status) {
+			results = append(results, rec)
+		}
+	}
+	return results, nil
+}
+
+// Get change request by id
+func (a *Change) Get(owner string, project string, id string) (*models.ChangeRequest, error) {
+	proj, err := findProject(a.Storage, owner, project)
+	if err != nil {
+		return nil, err
+	}
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil || !a.Storage.ChangeCRUD().IsExist(owner, proj.ID, oid) {
+		return nil, models.ErrNotFound(fmt.Sprintf("Change request [%s] is not found", id))
+	}
+	return a.Storage.ChangeCRUD().Get(owner, proj.ID, oid), nil
+}
+
+// Create pending change request of environment
+func (a *Change) Create(owner string, user string, project string, env string, data models.ChangeRequest) (*models.ChangeRequest, error) {
+	environment, err := activeEnvironment(a.Storage, owner, project, env)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.validate(owner, environment, &data); err != nil {
+		return nil, err
+	}
+
+	// fill up default values
+	data.ID = primitive.NilObjectID
+	data.ProjectID = environment.ProjectID
+	data.EnvironmentID = environment.ID
+	data.OwnerID = environment.OwnerID
+	data.Environment = environment.Code
+	data.Status = models.ChangeStatusPending
+	data.Author = user
+	data.Reviewer = ""
+	data.Comments = nil
+	data.RegDate = time.Now()
+	data.UpdDate = data.RegDate
+
+	a.Logger.Debugf("Change.Create: %+v", data)
+
+	resp, err := a.Storage.ChangeCRUD().Create(&data)
+	if err != nil {
+		return nil, models.ErrInternalServer(err.Error())
+	}
+
+	return resp, nil
+}
+
+// validate checks that change request can be applied to environment
+func (a *Change) validate(owner string, environment *models.Environment, data *models.ChangeRequest) error {
+	switch data.Entity {
+	case models.ChangeEntityParameter:
+		if data.Code == "" {
+			return models.ErrBadRequest("Code is invalid")
+		}
+		exists := a.Storage.ParameterCRUD().IsExist(owner, environment.ID, data.Code)
+		switch data.Action {
+		case models.ChangeActionCreate, models.ChangeActionUpdate:
+			if data.Parameter == nil {
+				return models.ErrBadRequest("Parameter is missed")
+			}
+			if err := data.Parameter.Validate(); err != nil {
+				return err
+			}
+			if data.Action == models.ChangeActionCreate && exists {
+				return models.ErrConflict("Code is already exist")
+			}
+			if data.Action == models.ChangeActionUpdate && !exists {
+				return models.ErrNotFound(fmt.Sprintf("Parameter with code [%s] is not found", data.Code))
+			}
+		case models.ChangeActionDelete:
+			if !exists {
+				return models.ErrNotFound(fmt.Sprintf("Parameter with code [%s] is not found", data.Code))
+			}
+		case models.ChangeActionRollback:
+			if !a.Storage.VersionCRUD().IsExist(owner, environment.ID, data.Code, data.Version) {
+				return models.ErrNotFound(fmt.Sprintf("Version [%d] of parameter [%s] is not found", data.Version, data.Code))
+			}
+		default:
+			return models.ErrBadRequest(fmt.Sprintf("Action [%s] is not supported", data.Action))
+		}
+	case models.ChangeEntityPromotion:
+		if data.Action != models.ChangeActionPromote || data.Promotion == nil || data.Preview == nil || data.Source == "" {
+			return models.ErrBadRequest("Promotion is invalid")
+		}
+	case models.ChangeEntityOverride:
+		if data.Instance == "" || data.Code == "" {
+			return models.ErrBadRequest("Override is invalid")
+		}
+		if !a.Storage.ObjectCRUD().IsExist(owner, environment.ProjectID, data.Instance) {
+			return models.ErrNotFound(fmt.Sprintf("Object with instance id [%s] is not found", data.Instance))
+		}
+		if !a.Storage.ParameterCRUD().IsExist(owner, environment.ID, data.Code) {
+			return models.ErrNotFound(fmt.Sprintf("Parameter with code [%s] is not found", data.Code))
+		}
+		switch data.Action {
+		case models.ChangeActionUpdate:
+			val, err := a.Storage.ParameterCRUD().Get(owner, environment.ID, data.Code).CheckValue(data.Value)
+			if err != nil {
+				return err
+			}
+			data.Value = val
+		case models.ChangeActionDelete:
+			item := a.Storage.ObjectCRUD().Get(owner, environment.ProjectID, data.Instance)
+			if item.Override(environment.Code, data.Code) == nil {
+				return models.ErrNotFound(fmt.Sprintf("Override of parameter [%s] is not found", data.Code))
+			}
+		default:
+			return models.ErrBadRequest(fmt.Sprintf("Action [%s] is not supported", data.Action))
+		}
+	default:
+		return models.ErrBadRequest(fmt.Sprintf("Entity [%s] is not supported", data.Entity))
+	}
+	return nil
+}
+
+// Comment adds user comment to change request
+func (a *Change) Comment(owner string, user string, project string, id string, text string) (*models.ChangeRequest, error) {
+	if text == "" {
+		return nil, models.ErrBadRequest("Comment is empty")
+	}
+	if _, err := activeProject(a.Storage, owner, project); err != nil {
+		return nil, err
+	}
+	item, err := a.Get(owner, project, id)
+	if err != nil {
+		return nil, err
+	}
+	item.Comments = append(item.Comments, &models.ChangeComment{
+		Author:  user,
+		Text:    text,
+		RegDate: time.Now(),
+	})
+	return a.save(item)
+}
+
+// Approve pending change request, author can't approve own changes
+func (a *Change) Approve(owner string, user string, project string, id string) (*models.ChangeRequest, error) {
+	item, err := a.review(owner, project, id)
+	if err != nil {
+		return nil, err
+	}
+	if item.Author == user {
+		return nil, models.ErrForbidden("Change request must be approved by another user")
+	}
+	item.Status = models.ChangeStatusApproved
+	item.Reviewer = user
+	return a.save(item)
+}
+
+// Reject pending change request
+func (a *Change) Reject(owner string, user string, project string, id string) (*models.ChangeRequest, error) {
+	item, err := a.review(owner, project, id)
+	if err != nil {
+		return nil, err
+	}
+	item.Status = models.ChangeStatusRejected
+	item.Reviewer = user
+	return a.save(item)
+}
+
+// Apply approved change request, applied parameter changes are returned
+// along with change request even when it is applied partially or can't be saved,
+// change request failed to apply is marked failed with error and must be proposed again
+func (a *Change) Apply(owner string, project string, id string) (*models.ChangeRequest, []*models.PromotionItem, error) {
+	if _, err := activeProject(a.Storage, owner, project); err != nil {
+		return nil, nil, err
+	}
+	item, err := a.Get(owner, project, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if item.Status != models.ChangeStatusApproved {
+		return nil, nil, models.ErrConflict(fmt.Sprintf("Change request [%s] is %s", id, item.Status))
+	}
+
+	a.Logger.Debugf("Change.Apply: %+v", item)
+
+	applied, err := a.apply(owner, project, item)
+	if err != nil {
+		item.Status = models.ChangeStatusFailed
+		item.Error = err.Error()
+		if _, saveErr := a.save(item); saveErr != nil {
+			a.Logger.Errorf("Change.Apply: %s", saveErr.Error())
+		}
+		return item, applied, err
+	}
+
+	item.Status = models.ChangeStatusApplied
+	resp, err := a.save(item)
+	if err != nil {
+		return item, applied, err
+	}
+	return resp, applied, nil
+}
+
+func (a *Change) apply(owner string, project string, item *models.ChangeRequest) ([]*models.PromotionItem, error) {
+	params := &Parameter{
+		Storage: a.Storage,
+		Events:  a.Events,
+		Ctx:     a.Ctx,
+		Config:  a.Config,
+		Logger:  a.Logger,
+	}
+	env := item.Environment
+
+	if item.Entity == models.ChangeEntityPromotion {
+		promotion := &Promotion{
+			Storage: a.Storage,
+			Events:  a.Events,
+			Ctx:     a.Ctx,
+			Config:  a.Config,
+			Logger:  a.Logger,
+		}
+		// source may have changed since review, only reviewed preview is applied
+		if item.Preview == nil {
+			return nil, models.ErrConflict(fmt.Sprintf("Change request [%s] has no reviewed preview, promotion must be proposed again", item.ID.Hex()))
+		}
+		resp, err := promotion.ApplyPrepared(owner, project, item.Preview)
+		if resp == nil {
+			return nil, err
+		}
+		return resp.Items, err
+	}
+
+	// object overrides are audited by caller, no parameter is changed
+	if item.Entity == models.ChangeEntityOverride {
+		objects := &Object{
+			Storage: a.Storage,
+			Events:  a.Events,
+			Ctx:     a.Ctx,
+			Config:  a.Config,
+			Logger:  a.Logger,
+		}
+		var err error
+		switch item.Action {
+		case models.ChangeActionUpdate:
+			_, err = objects.SetOverride(owner, project, item.Instance, env, item.Code, item.Value)
+		case models.ChangeActionDelete:
+			_, err = objects.RemoveOverride(owner, project, item.Instance, env, item.Code)
+		}
+		return nil, err
+	}
+
+	change := &models.PromotionItem{Code: item.Code, Action: item.Action}
+	change.Before, _ = params.Get(owner, project, env, item.Code)
+	var err error
+	switch item.Action {
+	case models.ChangeActionCreate:
+		change.After, err = params.Create(owner, project, env, *item.Parameter)
+	case models.ChangeActionUpdate:
+		data := *item.Parameter
+		data.Code = item.Code
+		change.After, err = params.Update(owner, project, env, data)
+	case models.ChangeActionDelete:
+		err = params.Delete(owner, project, env, item.Code)
+	case models.ChangeActionRollback:
+		change.After, err = params.Rollback(owner, project, env, item.Code, item.Version)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return []*models.PromotionItem{change}, nil
+}
+
+// review returns change request waiting for review
+func (a *Change) review(owner string, project string, id string) (*models.ChangeRequest, error) {
+	if _, err := activeProject(a.Storage, owner, project); err != nil {
+		return nil, err
+	}
+	item, err := a.Get(owner, project, id)
+	if err != nil {
+		return nil, err
+	}
+	if item.Status != models.ChangeStatusPending {
+		return nil, models.ErrConflict(fmt.Sprintf("Change request [%s] is %s", id, item.Status))
+	}
+	return item, nil
+}
+
+func (a *Change) save(item *models.ChangeRequest) (*models.ChangeRequest, error) {
+	item.UpdDate = time.Now()
+	resp, err := a.Storage.ChangeCRUD().Update(item)
+	if err != nil {
+		return nil, models.ErrInternalServer(err.Error())
+	}
+	return resp, nil
+}
@@ -0,0 +1,33 @@
+package service
+
+import (
+	"net/http"
+	"testing"
+
+	"bitbucket.org/toggly/toggly-server/models"
+)
+
+func TestFailedChangeIsNotAppliedAgain(t *testing.T) {
+	s := newTenant(t)
+	changeID, _ := s.seed(t, ownerA)
+	if _, err := s.changes.Approve(ownerA, "reviewer", "p1", changeID); err != nil {
+		t.Fatalf("Change.Approve: %s", err.Error())
+	}
+	// parameter is gone since review
+	if err := s.parameters.Delete(ownerA, "p1", "prod", "f"); err != nil {
+		t.Fatalf("Parameter.Delete: %s", err.Error())
+	}
+
+	if _, _, err := s.changes.Apply(ownerA, "p1", changeID); err == nil {
+		t.Fatal("change of deleted parameter is applied")
+	}
+	change, err := s.changes.Get(ownerA, "p1", changeID)
+	if err != nil || change.Status != models.ChangeStatusFailed || change.Error == "" {
+		t.Errorf("failure is not recorded: %+v %v", change, err)
+	}
+
+	_, _, err = s.changes.Apply(ownerA, "p1", changeID)
+	if e, ok := err.(*models.ErrStatusedResponse); !ok || e.Code != http.StatusConflict {
+		t.Errorf("failed change is applied again: %v", err)
+	}
+}
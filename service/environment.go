@@ -0,0 +1,178 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"bitbucket.org/toggly/toggly-server/models"
+	"bitbucket.org/toggly/toggly-server/storage"
+	"github.com/op/go-logging"
+)
+
+// Environment Service
+type Environment struct {
+	Storage storage.Storage
+	Ctx     context.Context
+	Config  *models.Config
+	Logger  *logging.Logger
+}
+
+// project returns project by code or not found error
+func (a *Environment) project(owner string, code string) (*models.Project, error) {
+	return findProject(a.Storage, owner, code)
+}
+
+// findEnvironment returns project environment by codes or not found error
+func findEnvironment(st storage.Storage, owner string, project string, code string) (*models.Environment, error) {
+	proj, err := findProject(st, owner, project)
+	if err != nil {
+		return nil, err
+	}
+	if !st.EnvironmentCRUD().IsExist(owner, proj.ID, code) {
+		return nil, models.ErrNotFound(fmt.Sprintf("Environment with code [%s] is not found", code))
+	}
+	return st.EnvironmentCRUD().Get(owner, proj.ID, code), nil
+}
+
+// activeEnvironment returns environment of project which is not archived
+func activeEnvironment(st storage.Storage, owner string, project string, code string) (*models.Environment, error) {
+	if _, err := activeProject(st, owner, project); err != nil {
+		return nil, err
+	}
+	return findEnvironment(st, owner, project, code)
+}
+
+// IsExist checks that environment exists by code
+func (a *Environment) IsExist(owner string, project string, code string) bool {
+	proj, err := a.project(owner, project)
+	if err != nil {
+		return false
+	}
+	return a.Storage.EnvironmentCRUD().IsExist(owner, proj.ID, code)
+}
+
+// Get environment by code
+func (a *Environment) Get(owner string, project string, code string) (*models.Environment, error) {
+	return findEnvironment(a.Storage, owner, project, code)
+}
+
+// List project environments
+func (a *Environment) List(owner string, project string) ([]*models.Environment, error) {
+	proj, err := a.project(owner, project)
+	if err != nil {
+		return nil, err
+	}
+	return a.Storage.EnvironmentCRUD().List(owner, proj.ID), nil
+}
+
+// Create environment
+func (a *Environment) Create(owner string, project string, data models.Environment) (*models.Environment, error) {
+	if data.Code == "" {
+		return nil, models.ErrBadRequest("Code is invalid")
+	}
+
+	proj, err := activeProject(a.Storage, owner, project)
+	if err != nil {
+		return nil, err
+	}
+
+	// fill up default values
+	data.ProjectID = proj.ID
+	data.OwnerID = proj.OwnerID
+	data.RegDate = time.Now()
+
+	a.Logger.Debugf("Environment.Create: %+v", data)
+
+	resp, err := a.Storage.EnvironmentCRUD().Create(&data)
+	if err != nil {
+		if storage.IsDuplicateKey(err) {
+			return nil, models.ErrConflict("Code is already exist")
+		}
+		return nil, models.ErrInternalServer(err.Error())
+	}
+
+	return resp, nil
+}
+
+// Update environment
+func (a *Environment) Update(owner string, project string, data models.Environment) (*models.Environment, error) {
+	a.Logger.Debugf("Environment.Update: %+v", data)
+
+	item, err := activeEnvironment(a.Storage, owner, project, data.Code)
+	if err != nil {
+		return nil, err
+	}
+
+	// revalue existing data
+	item.Description = data.Description
+	item.Protected = data.Protected
+
+	resp, err := a.Storage.EnvironmentCRUD().Update(item)
+	if err != nil {
+		return nil, models.ErrInternalServer(err.Error())
+	}
+
+	return resp, nil
+}
+
+// Delete environment with its parameters, their history, change requests, object overrides
+// and editor scopes, protected environments can't be deleted
+func (a *Environment) Delete(owner string, project string, code string) error {
+	item, err := activeEnvironment(a.Storage, owner, project, code)
+	if err != nil {
+		return err
+	}
+	if item.Protected {
+		return models.ErrForbidden(fmt.Sprintf("Environment [%s] is protected", code))
+	}
+
+	a.Logger.Debugf("Environment.Delete: %+v", item)
+
+	// keys of deleted environment are revoked
+	for _, key := range a.Storage.SDKKeyCRUD().List(owner, item.ID) {
+		if err := a.Storage.SDKKeyCRUD().Delete(owner, item.ID, key.ID); err != nil {
+			return models.ErrInternalServer(err.Error())
+		}
+	}
+
+	if err := a.Storage.ChangeCRUD().DeleteByEnvironment(owner, item.ProjectID, item.ID); err != nil {
+		return models.ErrInternalServer(err.Error())
+	}
+	if err := a.Storage.VersionCRUD().DeleteByEnvironment(owner, item.ID); err != nil {
+		return models.ErrInternalServer(err.Error())
+	}
+	for _, param := range a.Storage.ParameterCRUD().List(owner, item.ID) {
+		if err := a.Storage.ParameterCRUD().Delete(owner, item.ID, param.Code); err != nil {
+			return models.ErrInternalServer(err.Error())
+		}
+	}
+	for _, obj := range a.Storage.ObjectCRUD().List(owner, item.ProjectID) {
+		if !obj.RemoveOverrides(item.Code) {
+			continue
+		}
+		if _, err := a.Storage.ObjectCRUD().Update(obj); err != nil {
+			return models.ErrInternalServer(err.Error())
+		}
+	}
+
+	// environment created later with the same code is not granted to old editors
+	proj := a.Storage.ProjectCRUD().Get(owner, project)
+	scoped := false
+	for _, member := range proj.Members {
+		if member.RemoveEnvironment(item.Code) {
+			scoped = true
+		}
+	}
+	if scoped {
+		if _, err := a.Storage.ProjectCRUD().Update(proj); err != nil {
+			return models.ErrInternalServer(err.Error())
+		}
+	}
+
+	if err := a.Storage.EnvironmentCRUD().Delete(owner, item.ProjectID, item.Code); err != nil {
+		return models.ErrInternalServer(err.Error())
+	}
+
+	return nil
+}
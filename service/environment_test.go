@@ -0,0 +1,44 @@
+package service
+
+import (
+	"testing"
+
+	"bitbucket.org/toggly/toggly-server/models"
+)
+
+func TestDeletedEnvironmentLeavesEditorScopes(t *testing.T) {
+	s := newTenant(t)
+	s.seed(t, ownerA)
+	if _, err := s.environments.Create(ownerA, "p1", models.Environment{Code: "qa"}); err != nil {
+		t.Fatalf("Environment.Create: %s", err.Error())
+	}
+	for _, member := range []models.ProjectMember{
+		{UserID: "dev-editor", Role: models.RoleEditor, Environments: []string{"dev"}},
+		{UserID: "qa-editor", Role: models.RoleEditor, Environments: []string{"dev", "qa"}},
+	} {
+		if _, err := s.projects.SetMember(ownerA, "p1", member); err != nil {
+			t.Fatalf("Project.SetMember: %s", err.Error())
+		}
+	}
+
+	if err := s.environments.Delete(ownerA, "p1", "dev"); err != nil {
+		t.Fatalf("Environment.Delete: %s", err.Error())
+	}
+	if _, err := s.environments.Create(ownerA, "p1", models.Environment{Code: "dev"}); err != nil {
+		t.Fatalf("Environment.Create: %s", err.Error())
+	}
+
+	proj := s.projects.Get(ownerA, "p1")
+	if m := proj.Member("dev-editor"); m == nil || m.Role != models.RoleViewer || len(m.Environments) != 0 {
+		t.Errorf("editor of deleted environment is %+v", m)
+	}
+	if m := proj.Member("qa-editor"); m == nil || m.Role != models.RoleEditor || len(m.Environments) != 1 || m.Environments[0] != "qa" {
+		t.Errorf("editor of remaining environment is %+v", m)
+	}
+	access := &Access{Storage: s.projects.Storage}
+	for _, user := range []string{"dev-editor", "qa-editor"} {
+		if err := access.Check(ownerA, user, "p1", "dev", models.PermissionWrite); err == nil {
+			t.Errorf("%s can change recreated environment", user)
+		}
+	}
+}
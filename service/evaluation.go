@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+
+	"bitbucket.org/toggly/toggly-server/models"
+	"bitbucket.org/toggly/toggly-server/storage"
+	"github.com/op/go-logging"
+)
+
+// Evaluation Service, resolves parameter values for client SDKs
+type Evaluation struct {
+	Storage storage.Storage
+	Ctx     context.Context
+	Config  *models.Config
+	Logger  *logging.Logger
+}
+
+// Values returns flat code to value document of environment parameters,
+// package narrows parameters down, instance id applies object overrides
+// and attributes are matched against targeting rules, archived projects are not evaluated
+func (a *Evaluation) Values(owner string, project string, env string, pkg string, instanceID string, attrs map[string]interface{}) (map[string]interface{}, error) {
+	return a.values(owner, project, env, pkg, instanceID, attrs, false)
+}
+
+// ClientValues returns values of parameters exposed to client SDKs,
+// objects are not resolved as client keys are shipped to end users
+func (a *Evaluation) ClientValues(owner string, project string, env string, pkg string, attrs map[string]interface{}) (map[string]interface{}, error) {
+	return a.values(owner, project, env, pkg, "", attrs, true)
+}
+
+// KeyValues returns values available to SDK key, client keys can't pick objects by instance id,
+// requests without key are evaluated as server ones
+func (a *Evaluation) KeyValues(key *models.SDKKey, owner string, project string, env string, pkg string, instanceID string, attrs map[string]interface{}) (map[string]interface{}, error) {
+	if key == nil || key.Kind != models.SDKKeyClient {
+		return a.Values(owner, project, env, pkg, instanceID, attrs)
+	}
+	if instanceID != "" {
+		return nil, models.ErrForbidden("Client SDK key can't evaluate objects")
+	}
+	return a.ClientValues(owner, project, env, pkg, attrs)
+}
+
+func (a *Evaluation) values(owner string, project string, env string, pkg string, instanceID string, attrs map[string]interface{}, client bool) (map[string]interface{}, error) {
+	if _, err := activeProject(a.Storage, owner, project); err != nil {
+		return nil, err
+	}
+	if pkg != "" {
+		if _, err := findPackage(a.Storage, owner, project, pkg); err != nil {
+			return nil, err
+		}
+	}
+	return resolveValues(a.Storage, owner, project, env, pkg, instanceID, attrs, client)
+}
+
+// resolveValues loads environment parameters and evaluates them, unknown instance has no overrides,
+// client narrows parameters down to the ones exposed to client SDKs
+func resolveValues(st storage.Storage, owner string, project string, env string, pkg string, instanceID string, attrs map[string]interface{}, client bool) (map[string]interface{}, error) {
+	environment, err := findEnvironment(st, owner, project, env)
+	if err != nil {
+		return nil, err
+	}
+	var obj *models.Object
+	if instanceID != "" && st.ObjectCRUD().IsExist(owner, environment.ProjectID, instanceID) {
+		obj = st.ObjectCRUD().Get(owner, environment.ProjectID, instanceID)
+	}
+	var params []*models.Parameter
+	if pkg != "" {
+		params = st.ParameterCRUD().ListByPackage(owner, environment.ID, pkg)
+	} else {
+		params = st.ParameterCRUD().List(owner, environment.ID)
+	}
+	if client {
+		exposed := make([]*models.Parameter, 0, len(params))
+		for _, p := range params {
+			if p.Client {
+				exposed = append(exposed, p)
+			}
+		}
+		params = exposed
+	}
+	return evaluate(params, environment, instanceID, obj, attrs), nil
+}
+
+// evaluate resolves parameters into code to value map,
+// object overrides take precedence over targeting rules and environment values
+func evaluate(params []*models.Parameter, env *models.Environment, instanceID string, obj *models.Object, attrs map[string]interface{}) map[string]interface{} {
+	ctx := evaluationContext(instanceID, obj, attrs)
+	values := make(map[string]interface{}, len(params))
+	for _, p := range params {
+		values[p.Code] = p.Evaluate(ctx)
+		if obj == nil {
+			continue
+		}
+		if ovr := obj.Override(env.Code, p.Code); ovr != nil {
+			// skip overrides which don't fit parameter anymore
+			if val, err := p.CheckValue(ovr.Value); err == nil {
+				values[p.Code] = val
+			}
+		}
+	}
+	return values
+}
+
+// evaluationContext merges object properties with request attributes, request attributes win,
+// instance id is available for rules and rollouts even when object is unknown
+func evaluationContext(instanceID string, obj *models.Object, attrs map[string]interface{}) map[string]interface{} {
+	ctx := make(map[string]interface{})
+	if obj != nil {
+		for key, val := range obj.Props {
+			ctx[key] = val
+		}
+	}
+	if instanceID != "" {
+		ctx["instanceId"] = instanceID
+	}
+	for key, val := range attrs {
+		ctx[key] = val
+	}
+	return ctx
+}
@@ -0,0 +1,140 @@
+package service
+
+import (
+	"sync"
+	"time"
+
+	"bitbucket.org/toggly/toggly-server/models"
+)
+
+// subscriberBuffer is a number of events subscriber may lag behind before it is dropped
+const subscriberBuffer = 64
+
+// Events is an in-memory broker of configuration change events,
+// it keeps limited history to let subscribers resume after reconnect
+type Events struct {
+	mu      sync.Mutex
+	seq     uint64
+	size    int
+	history []*models.Event
+	subs    map[chan *models.Event]func(evt *models.Event) bool
+}
+
+// Subscription struct
+type Subscription struct {
+	// C delivers events, it is closed when subscriber is too slow or cancelled
+	C <-chan *models.Event
+	// Missed contains events published after requested event id
+	Missed []*models.Event
+	// Resumed is false when requested event id is out of history
+	Resumed bool
+	// LastID is an id of the last event published before subscription
+	LastID uint64
+	cancel func()
+}
+
+// Cancel stops subscription
+func (s *Subscription) Cancel() {
+	s.cancel()
+}
+
+// NewEvents creates broker keeping size events in history
+func NewEvents(size int) *Events {
+	return &Events{
+		size:    size,
+		history: make([]*models.Event, 0, size),
+		subs:    make(map[chan *models.Event]func(evt *models.Event) bool),
+	}
+}
+
+// Publish assigns event id and delivers event to subscribers
+func (e *Events) Publish(evt *models.Event) {
+	if e == nil {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.seq++
+	evt.ID = e.seq
+	evt.Time = time.Now()
+
+	if len(e.history) == e.size {
+		e.history = append(e.history[:0], e.history[1:]...)
+	}
+	e.history = append(e.history, evt)
+
+	for ch, filter := range e.subs {
+		if !filter(evt) {
+			continue
+		}
+		select {
+		case ch <- evt:
+		default:
+			// drop slow subscriber, it resumes from the last received event
+			delete(e.subs, ch)
+			close(ch)
+		}
+	}
+}
+
+// Subscribe starts delivering events matching filter,
+// events published after lastID are returned as missed when history allows
+func (e *Events) Subscribe(lastID uint64, filter func(evt *models.Event) bool) *Subscription {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	ch := make(chan *models.Event, subscriberBuffer)
+	e.subs[ch] = filter
+
+	sub := &Subscription{
+		C:      ch,
+		LastID: e.seq,
+		cancel: func() {
+			e.mu.Lock()
+			defer e.mu.Unlock()
+			if _, ok := e.subs[ch]; ok {
+				delete(e.subs, ch)
+				close(ch)
+			}
+		},
+	}
+
+	oldest := e.seq - uint64(len(e.history))
+	if lastID == 0 || lastID < oldest || lastID > e.seq {
+		return sub
+	}
+	sub.Resumed = true
+	for _, evt := range e.history {
+		if evt.ID > lastID && filter(evt) {
+			sub.Missed = append(sub.Missed, evt)
+		}
+	}
+	return sub
+}
+
+// publishParameter sends parameter change event, client is set when
+// parameter is or was exposed to client SDKs
+func (e *Events) publishParameter(owner string, project string, env string, action string, code string, client bool) {
+	e.Publish(&models.Event{
+		OwnerID:     owner,
+		Project:     project,
+		Environment: env,
+		Entity:      models.EventEntityParameter,
+		Action:      action,
+		Code:        code,
+		Client:      client,
+	})
+}
+
+// publish sends change event of project scoped entity
+func (e *Events) publish(owner string, project string, env string, entity string, action string, code string) {
+	e.Publish(&models.Event{
+		OwnerID:     owner,
+		Project:     project,
+		Environment: env,
+		Entity:      entity,
+		Action:      action,
+		Code:        code,
+	})
+}
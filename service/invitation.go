@@ -0,0 +1,293 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"bitbucket.org/toggly/toggly-server/models"
+	"bitbucket.org/toggly/toggly-server/storage"
+	"github.com/op/go-logging"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// defaultInvitationTTL is used when request has no ttl
+const defaultInvitationTTL = 7 * 24 * time.Hour
+
+// Invitation Service
+type Invitation struct {
+	Storage storage.Storage
+	Ctx     context.Context
+	Config  *models.Config
+	Logger  *logging.Logger
+}
+
+// ListOrganization returns invitations to organization, user must be its admin
+func (a *Invitation) ListOrganization(user string, code string) ([]*models.Invitation, error) {
+	org, err := findOrganization(a.Storage, user, code)
+	if err != nil {
+		return nil, err
+	}
+	if !org.IsAdmin(user) {
+		return nil, models.ErrForbidden("Only admins manage organization")
+	}
+	return a.Storage.InvitationCRUD().List(org.ID.Hex(), primitive.NilObjectID), nil
+}
+
+// ListProject returns invitations to project
+func (a *Invitation) ListProject(owner string, code string) ([]*models.Invitation, error) {
+	project, err := findProject(a.Storage, owner, code)
+	if err != nil {
+		return nil, err
+	}
+	return a.Storage.InvitationCRUD().List(owner, project.ID), nil
+}
+
+// Pending returns invitations waiting for user with email
+func (a *Invitation) Pending(email string) []*models.Invitation {
+	now := time.Now()
+	results := make([]*models.Invitation, 0)
+	for _, rec := range a.Storage.InvitationCRUD().ListByEmail(normalizeEmail(email)) {
+		if rec.Status == models.InvitationStatusPending && !rec.IsExpired(now) {
+			results = append(results, rec)
+		}
+	}
+	return results
+}
+
+// InviteToOrganization creates invitation to organization, only owners invite owners
+func (a *Invitation) InviteToOrganization(user string, code string, data models.InvitationRequest, ttl time.Duration) (*models.Invitation, error) {
+	if !models.IsOrgRole(data.Role) {
+		return nil, models.ErrBadRequest(fmt.Sprintf("Role [%s] is unknown", data.Role))
+	}
+	org, err := findOrganization(a.Storage, user, code)
+	if err != nil {
+		return nil, err
+	}
+	if !org.IsAdmin(user) {
+		return nil, models.ErrForbidden("Only admins manage organization")
+	}
+	if data.Role == models.OrgRoleOwner && !org.IsOwner(user) {
+		return nil, models.ErrForbidden("Only owners grant or revoke owner role")
+	}
+
+	return a.create(&models.Invitation{
+		OwnerID:      org.ID.Hex(),
+		Organization: org.Code,
+		Email:        data.Email,
+		Role:         data.Role,
+		InvitedBy:    user,
+	}, ttl)
+}
+
+// InviteToProject creates invitation to project, project roles are checked by caller
+func (a *Invitation) InviteToProject(owner string, user string, code string, data models.InvitationRequest, ttl time.Duration) (*models.Invitation, error) {
+	project, err := findProject(a.Storage, owner, code)
+	if err != nil {
+		return nil, err
+	}
+	member := models.ProjectMember{Role: data.Role, Environments: data.Environments}
+	if err := validateMember(a.Storage, project, member); err != nil {
+		return nil, err
+	}
+	// session user always owns itself, so personal projects can't be reached by others
+	org := ownerOrganization(a.Storage, owner)
+	if org == nil && a.Config.Auth != nil && a.Config.Auth.Mode == models.AuthModeSession {
+		return nil, models.ErrBadRequest("Only organization projects can be shared")
+	}
+	invitation := &models.Invitation{
+		OwnerID:      owner,
+		ProjectID:    project.ID,
+		Project:      project.Code,
+		Email:        data.Email,
+		Role:         data.Role,
+		Environments: data.Environments,
+		InvitedBy:    user,
+	}
+	if org != nil {
+		invitation.Organization = org.Code
+	}
+
+	return a.create(invitation, ttl)
+}
+
+// revoke cancels pending invitation to organization or project
+func (a *Invitation) revoke(owner string, projectID primitive.ObjectID, id string) error {
+	item := a.get(id)
+	if item == nil || item.OwnerID != owner || item.ProjectID != projectID {
+		return models.ErrNotFound(fmt.Sprintf("Invitation [%s] is not found", id))
+	}
+	if item.Status != models.InvitationStatusPending {
+		return models.ErrConflict(fmt.Sprintf("Invitation is already %s", item.Status))
+	}
+
+	a.Logger.Debugf("Invitation.Revoke: %s", id)
+
+	_, err := a.resolve(item, models.InvitationStatusRevoked, "")
+	return err
+}
+
+// RevokeOrganization cancels pending invitation to organization, user must be its admin
+func (a *Invitation) RevokeOrganization(user string, code string, id string) error {
+	org, err := findOrganization(a.Storage, user, code)
+	if err != nil {
+		return err
+	}
+	if !org.IsAdmin(user) {
+		return models.ErrForbidden("Only admins manage organization")
+	}
+	return a.revoke(org.ID.Hex(), primitive.NilObjectID, id)
+}
+
+// RevokeProject cancels pending invitation to project
+func (a *Invitation) RevokeProject(owner string, code string, id string) error {
+	project, err := findProject(a.Storage, owner, code)
+	if err != nil {
+		return err
+	}
+	return a.revoke(owner, project.ID, id)
+}
+
+// ByToken returns invitation from link, link works for any user holding it
+func (a *Invitation) ByToken(token string) (*models.Invitation, error) {
+	item := a.Storage.InvitationCRUD().Find(hashKey(token))
+	if item == nil {
+		return nil, models.ErrNotFound("Invitation is not found")
+	}
+	return item, nil
+}
+
+// ByEmail returns invitation addressed to user with email
+func (a *Invitation) ByEmail(email string, id string) (*models.Invitation, error) {
+	item := a.get(id)
+	if item == nil || item.Email != normalizeEmail(email) {
+		return nil, models.ErrNotFound(fmt.Sprintf("Invitation [%s] is not found", id))
+	}
+	return item, nil
+}
+
+// Accept makes user a member of organization and project from invitation,
+// project invitation within organization adds user to organization as well
+func (a *Invitation) Accept(user string, item *models.Invitation) (*models.Invitation, error) {
+	if err := checkPending(item); err != nil {
+		return nil, err
+	}
+
+	a.Logger.Debugf("Invitation.Accept: %s by %s", item.ID.Hex(), user)
+
+	if item.Organization != "" {
+		org := a.Storage.OrganizationCRUD().Get(item.Organization)
+		if org == nil {
+			return nil, models.ErrNotFound(fmt.Sprintf("Organization with code [%s] is not found", item.Organization))
+		}
+		if org.Member(user) == nil {
+			role := models.OrgRoleMember
+			if item.Project == "" {
+				role = item.Role
+			}
+			org.Members = append(org.Members, &models.OrganizationMember{UserID: user, Role: role})
+			if _, err := a.Storage.OrganizationCRUD().Update(org); err != nil {
+				return nil, models.ErrInternalServer(err.Error())
+			}
+		}
+	}
+
+	if item.Project != "" {
+		project, err := findProject(a.Storage, item.OwnerID, item.Project)
+		if err != nil {
+			return nil, err
+		}
+		grant := &models.ProjectMember{UserID: user, Role: item.Role, Environments: item.Environments}
+		// invitation never lowers existing role
+		if member := project.Member(user); member == nil {
+			project.Members = append(project.Members, grant)
+		} else if grant.Outranks(member) {
+			*member = *grant
+		}
+		if _, err := a.Storage.ProjectCRUD().Update(project); err != nil {
+			return nil, models.ErrInternalServer(err.Error())
+		}
+	}
+
+	return a.resolve(item, models.InvitationStatusAccepted, user)
+}
+
+// Decline rejects invitation
+func (a *Invitation) Decline(user string, item *models.Invitation) (*models.Invitation, error) {
+	if err := checkPending(item); err != nil {
+		return nil, err
+	}
+
+	a.Logger.Debugf("Invitation.Decline: %s by %s", item.ID.Hex(), user)
+
+	return a.resolve(item, models.InvitationStatusDeclined, "")
+}
+
+func (a *Invitation) create(data *models.Invitation, ttl time.Duration) (*models.Invitation, error) {
+	data.Email = normalizeEmail(data.Email)
+	if !strings.Contains(data.Email, "@") {
+		return nil, models.ErrBadRequest("Email is invalid")
+	}
+	if ttl < 0 {
+		return nil, models.ErrBadRequest("TTL is invalid")
+	}
+	if ttl == 0 {
+		ttl = defaultInvitationTTL
+	}
+
+	secret := make([]byte, 24)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, models.ErrInternalServer(err.Error())
+	}
+	token := hex.EncodeToString(secret)
+
+	now := time.Now()
+	data.Hash = hashKey(token)
+	data.Status = models.InvitationStatusPending
+	data.ExpiresAt = now.Add(ttl)
+	data.RegDate = now
+	data.UpdDate = now
+
+	a.Logger.Debugf("Invitation.Create: %s as %s", data.Email, data.Role)
+
+	resp, err := a.Storage.InvitationCRUD().Create(data)
+	if err != nil {
+		return nil, models.ErrInternalServer(err.Error())
+	}
+	resp.Token = token
+
+	return resp, nil
+}
+
+func (a *Invitation) get(id string) *models.Invitation {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil
+	}
+	return a.Storage.InvitationCRUD().Get(oid)
+}
+
+func (a *Invitation) resolve(item *models.Invitation, status string, user string) (*models.Invitation, error) {
+	item.Status = status
+	item.AcceptedBy = user
+	item.UpdDate = time.Now()
+	resp, err := a.Storage.InvitationCRUD().Update(item)
+	if err != nil {
+		return nil, models.ErrInternalServer(err.Error())
+	}
+	return resp, nil
+}
+
+// checkPending verifies that invitation can be answered
+func checkPending(item *models.Invitation) error {
+	if item.Status != models.InvitationStatusPending {
+		return models.ErrConflict(fmt.Sprintf("Invitation is already %s", item.Status))
+	}
+	if item.IsExpired(time.Now()) {
+		return models.ErrConflict("Invitation is expired")
+	}
+	return nil
+}
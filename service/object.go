@@ -0,0 +1,209 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"bitbucket.org/toggly/toggly-server/models"
+	"bitbucket.org/toggly/toggly-server/storage"
+	"github.com/op/go-logging"
+)
+
+// Object Service
+type Object struct {
+	Storage storage.Storage
+	Events  *Events
+	Ctx     context.Context
+	Config  *models.Config
+	Logger  *logging.Logger
+}
+
+// findObject returns project object by instance id or not found error
+func findObject(st storage.Storage, owner string, project string, instanceID string) (*models.Object, error) {
+	proj, err := findProject(st, owner, project)
+	if err != nil {
+		return nil, err
+	}
+	if !st.ObjectCRUD().IsExist(owner, proj.ID, instanceID) {
+		return nil, models.ErrNotFound(fmt.Sprintf("Object with instance id [%s] is not found", instanceID))
+	}
+	return st.ObjectCRUD().Get(owner, proj.ID, instanceID), nil
+}
+
+// Get object by instance id
+func (a *Object) Get(owner string, project string, instanceID string) (*models.Object, error) {
+	return findObject(a.Storage, owner, project, instanceID)
+}
+
+// List project objects
+func (a *Object) List(owner string, project string) ([]*models.Object, error) {
+	proj, err := findProject(a.Storage, owner, project)
+	if err != nil {
+		return nil, err
+	}
+	return a.Storage.ObjectCRUD().List(owner, proj.ID), nil
+}
+
+// Create object
+func (a *Object) Create(owner string, project string, data models.Object) (*models.Object, error) {
+	if data.InstanceID == "" {
+		return nil, models.ErrBadRequest("Instance id is invalid")
+	}
+
+	proj, err := activeProject(a.Storage, owner, project)
+	if err != nil {
+		return nil, err
+	}
+
+	// fill up default values
+	data.ProjectID = proj.ID
+	data.OwnerID = proj.OwnerID
+	data.Overrides = nil
+	data.RegDate = time.Now()
+
+	a.Logger.Debugf("Object.Create: %+v", data)
+
+	resp, err := a.Storage.ObjectCRUD().Create(&data)
+	if err != nil {
+		if storage.IsDuplicateKey(err) {
+			return nil, models.ErrConflict("Instance id is already exist")
+		}
+		return nil, models.ErrInternalServer(err.Error())
+	}
+
+	return resp, nil
+}
+
+// Update object
+func (a *Object) Update(owner string, project string, data models.Object) (*models.Object, error) {
+	a.Logger.Debugf("Object.Update: %+v", data)
+
+	if _, err := activeProject(a.Storage, owner, project); err != nil {
+		return nil, err
+	}
+	item, err := a.Get(owner, project, data.InstanceID)
+	if err != nil {
+		return nil, err
+	}
+
+	// revalue existing data, overrides are managed separately
+	item.Name = data.Name
+	item.Props = data.Props
+
+	resp, err := a.Storage.ObjectCRUD().Update(item)
+	if err != nil {
+		return nil, models.ErrInternalServer(err.Error())
+	}
+
+	return resp, nil
+}
+
+// Delete object, overrides of protected environments are removed
+// by approved change requests before
+func (a *Object) Delete(owner string, project string, instanceID string) error {
+	if _, err := activeProject(a.Storage, owner, project); err != nil {
+		return err
+	}
+	item, err := a.Get(owner, project, instanceID)
+	if err != nil {
+		return err
+	}
+	for _, ovr := range item.Overrides {
+		if environment, err := findEnvironment(a.Storage, owner, project, ovr.Environment); err == nil && environment.Protected {
+			return models.ErrForbidden(fmt.Sprintf("Object [%s] has overrides in protected environment [%s]", instanceID, ovr.Environment))
+		}
+	}
+
+	a.Logger.Debugf("Object.Delete: %+v", item)
+
+	if err := a.Storage.ObjectCRUD().Delete(owner, item.ProjectID, item.InstanceID); err != nil {
+		return models.ErrInternalServer(err.Error())
+	}
+
+	return nil
+}
+
+// SetOverride sets object specific value of environment parameter
+func (a *Object) SetOverride(owner string, project string, instanceID string, env string, param string, value interface{}) (*models.Object, error) {
+	if _, err := activeProject(a.Storage, owner, project); err != nil {
+		return nil, err
+	}
+	item, err := a.Get(owner, project, instanceID)
+	if err != nil {
+		return nil, err
+	}
+	environment, err := findEnvironment(a.Storage, owner, project, env)
+	if err != nil {
+		return nil, err
+	}
+	if !a.Storage.ParameterCRUD().IsExist(owner, environment.ID, param) {
+		return nil, models.ErrNotFound(fmt.Sprintf("Parameter with code [%s] is not found", param))
+	}
+	val, err := a.Storage.ParameterCRUD().Get(owner, environment.ID, param).CheckValue(value)
+	if err != nil {
+		return nil, err
+	}
+
+	item.SetOverride(environment.Code, param, val)
+
+	a.Logger.Debugf("Object.SetOverride: %+v", item)
+
+	resp, err := a.Storage.ObjectCRUD().Update(item)
+	if err != nil {
+		return nil, models.ErrInternalServer(err.Error())
+	}
+
+	a.Events.Publish(&models.Event{
+		OwnerID:     owner,
+		Project:     project,
+		Environment: env,
+		Entity:      models.EventEntityOverride,
+		Action:      models.EventActionUpdate,
+		Code:        param,
+		Instance:    instanceID,
+	})
+
+	return resp, nil
+}
+
+// RemoveOverride removes object specific value of environment parameter
+func (a *Object) RemoveOverride(owner string, project string, instanceID string, env string, param string) (*models.Object, error) {
+	if _, err := activeProject(a.Storage, owner, project); err != nil {
+		return nil, err
+	}
+	item, err := a.Get(owner, project, instanceID)
+	if err != nil {
+		return nil, err
+	}
+	if !item.RemoveOverride(env, param) {
+		return nil, models.ErrNotFound(fmt.Sprintf("Override of parameter [%s] is not found", param))
+	}
+
+	a.Logger.Debugf("Object.RemoveOverride: %+v", item)
+
+	resp, err := a.Storage.ObjectCRUD().Update(item)
+	if err != nil {
+		return nil, models.ErrInternalServer(err.Error())
+	}
+
+	a.Events.Publish(&models.Event{
+		OwnerID:     owner,
+		Project:     project,
+		Environment: env,
+		Entity:      models.EventEntityOverride,
+		Action:      models.EventActionDelete,
+		Code:        param,
+		Instance:    instanceID,
+	})
+
+	return resp, nil
+}
+
+// Values returns environment parameter values resolved for object
+func (a *Object) Values(owner string, project string, instanceID string, env string) (map[string]interface{}, error) {
+	if _, err := a.Get(owner, project, instanceID); err != nil {
+		return nil, err
+	}
+	return resolveValues(a.Storage, owner, project, env, "", instanceID, nil, false)
+}
@@ -0,0 +1,31 @@
+package service
+
+import (
+	"net/http"
+	"testing"
+
+	"bitbucket.org/toggly/toggly-server/models"
+)
+
+func TestDeleteObjectWithProtectedOverride(t *testing.T) {
+	s := newTenant(t)
+	s.seed(t, ownerA)
+	if _, err := s.objects.SetOverride(ownerA, "p1", "u1", "prod", "f", false); err != nil {
+		t.Fatalf("Object.SetOverride: %s", err.Error())
+	}
+
+	err := s.objects.Delete(ownerA, "p1", "u1")
+	if e, ok := err.(*models.ErrStatusedResponse); !ok || e.Code != http.StatusForbidden {
+		t.Fatalf("object with protected override is deleted: %v", err)
+	}
+	if _, err := s.objects.Get(ownerA, "p1", "u1"); err != nil {
+		t.Fatalf("Object.Get: %s", err.Error())
+	}
+
+	if _, err := s.objects.RemoveOverride(ownerA, "p1", "u1", "prod", "f"); err != nil {
+		t.Fatalf("Object.RemoveOverride: %s", err.Error())
+	}
+	if err := s.objects.Delete(ownerA, "p1", "u1"); err != nil {
+		t.Errorf("Object.Delete: %s", err.Error())
+	}
+}
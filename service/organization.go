@@ -0,0 +1,287 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"bitbucket.org/toggly/toggly-server/models"
+	"bitbucket.org/toggly/toggly-server/storage"
+	"github.com/op/go-logging"
+)
+
+// Organization Service, every method acts on behalf of user
+type Organization struct {
+	Storage storage.Storage
+	Ctx     context.Context
+	Config  *models.Config
+	Logger  *logging.Logger
+}
+
+// List organizations user is a member of
+func (a *Organization) List(user string) []*models.Organization {
+	return a.Storage.OrganizationCRUD().List(user)
+}
+
+// Get organization by code, organization is hidden from non-members
+func (a *Organization) Get(user string, code string) (*models.Organization, error) {
+	return findOrganization(a.Storage, user, code)
+}
+
+// Create organization, creator becomes its owner
+func (a *Organization) Create(user string, data models.Organization) (*models.Organization, error) {
+	if data.Code == "" {
+		return nil, models.ErrBadRequest("Code is invalid")
+	}
+	if data.Name == "" {
+		return nil, models.ErrBadRequest("Name is invalid")
+	}
+
+	data.Members = []*models.OrganizationMember{{UserID: user, Role: models.OrgRoleOwner}}
+	data.Teams = make([]*models.Team, 0)
+	data.RegDate = time.Now()
+
+	a.Logger.Debugf("Organization.Create: %+v", data)
+
+	resp, err := a.Storage.OrganizationCRUD().Create(&data)
+	if err != nil {
+		if storage.IsDuplicateKey(err) {
+			return nil, models.ErrConflict("Code is already exist")
+		}
+		return nil, models.ErrInternalServer(err.Error())
+	}
+
+	return resp, nil
+}
+
+// Update organization, only name and description are changed
+func (a *Organization) Update(user string, code string, data models.Organization) (*models.Organization, error) {
+	if data.Code != "" && data.Code != code {
+		return nil, models.ErrBadRequest("Code is immutable")
+	}
+	if data.Name == "" {
+		return nil, models.ErrBadRequest("Name is invalid")
+	}
+
+	item, err := findOrganization(a.Storage, user, code)
+	if err != nil {
+		return nil, err
+	}
+	if !item.IsAdmin(user) {
+		return nil, models.ErrForbidden("Only admins manage organization")
+	}
+
+	a.Logger.Debugf("Organization.Update: %+v", data)
+
+	item.Name = data.Name
+	item.Description = data.Description
+
+	return a.update(item)
+}
+
+// Delete organization, organization with projects can't be deleted
+func (a *Organization) Delete(user string, code string) error {
+	item, err := findOrganization(a.Storage, user, code)
+	if err != nil {
+		return err
+	}
+	if !item.IsOwner(user) {
+		return models.ErrForbidden("Only owners delete organization")
+	}
+	if len(a.Storage.ProjectCRUD().List(item.ID.Hex())) != 0 {
+		return models.ErrConflict("Organization has projects")
+	}
+
+	a.Logger.Debugf("Organization.Delete: %+v", item)
+
+	if err := a.Storage.OrganizationCRUD().Delete(item.Code); err != nil {
+		return models.ErrInternalServer(err.Error())
+	}
+
+	return nil
+}
+
+// SetMember adds user to organization or changes member role,
+// only owners grant or revoke owner role
+func (a *Organization) SetMember(user string, code string, data models.OrganizationMember) (*models.Organization, error) {
+	if data.UserID == "" {
+		return nil, models.ErrBadRequest("User is invalid")
+	}
+	if !models.IsOrgRole(data.Role) {
+		return nil, models.ErrBadRequest(fmt.Sprintf("Role [%s] is unknown", data.Role))
+	}
+
+	item, err := findOrganization(a.Storage, user, code)
+	if err != nil {
+		return nil, err
+	}
+	if !item.IsAdmin(user) {
+		return nil, models.ErrForbidden("Only admins manage organization")
+	}
+	member := item.Member(data.UserID)
+	if (data.Role == models.OrgRoleOwner || item.IsOwner(data.UserID)) && !item.IsOwner(user) {
+		return nil, models.ErrForbidden("Only owners grant or revoke owner role")
+	}
+	if item.IsOwner(data.UserID) && data.Role != models.OrgRoleOwner && ownersCount(item) == 1 {
+		return nil, models.ErrConflict("Organization must have an owner")
+	}
+
+	a.Logger.Debugf("Organization.SetMember: %s -> %+v", code, data)
+
+	if member != nil {
+		member.Role = data.Role
+	} else {
+		item.Members = append(item.Members, &data)
+	}
+
+	return a.update(item)
+}
+
+// RemoveMember takes user out of organization and its teams, members may leave by themselves
+func (a *Organization) RemoveMember(user string, code string, target string) (*models.Organization, error) {
+	item, err := findOrganization(a.Storage, user, code)
+	if err != nil {
+		return nil, err
+	}
+	if item.Member(target) == nil {
+		return nil, models.ErrNotFound(fmt.Sprintf("Member [%s] is not found", target))
+	}
+	if target != user && !item.IsAdmin(user) {
+		return nil, models.ErrForbidden("Only admins manage organization")
+	}
+	if item.IsOwner(target) {
+		if !item.IsOwner(user) {
+			return nil, models.ErrForbidden("Only owners grant or revoke owner role")
+		}
+		if ownersCount(item) == 1 {
+			return nil, models.ErrConflict("Organization must have an owner")
+		}
+	}
+
+	a.Logger.Debugf("Organization.RemoveMember: %s -> %s", code, target)
+
+	members := make([]*models.OrganizationMember, 0, len(item.Members))
+	for _, member := range item.Members {
+		if member.UserID != target {
+			members = append(members, member)
+		}
+	}
+	item.Members = members
+	for _, team := range item.Teams {
+		team.Members = without(team.Members, target)
+	}
+
+	return a.update(item)
+}
+
+// SetTeam creates or replaces team, team members must be organization members
+func (a *Organization) SetTeam(user string, code string, data models.Team) (*models.Organization, error) {
+	if data.Code == "" {
+		return nil, models.ErrBadRequest("Code is invalid")
+	}
+
+	item, err := findOrganization(a.Storage, user, code)
+	if err != nil {
+		return nil, err
+	}
+	if !item.IsAdmin(user) {
+		return nil, models.ErrForbidden("Only admins manage organization")
+	}
+	if data.Members == nil {
+		data.Members = make([]string, 0)
+	}
+	for _, member := range data.Members {
+		if item.Member(member) == nil {
+			return nil, models.ErrBadRequest(fmt.Sprintf("User [%s] is not an organization member", member))
+		}
+	}
+
+	a.Logger.Debugf("Organization.SetTeam: %s -> %+v", code, data)
+
+	if team := item.Team(data.Code); team != nil {
+		*team = data
+	} else {
+		item.Teams = append(item.Teams, &data)
+	}
+
+	return a.update(item)
+}
+
+// RemoveTeam deletes team with project roles granted to it
+func (a *Organization) RemoveTeam(user string, code string, team string) (*models.Organization, error) {
+	item, err := findOrganization(a.Storage, user, code)
+	if err != nil {
+		return nil, err
+	}
+	if !item.IsAdmin(user) {
+		return nil, models.ErrForbidden("Only admins manage organization")
+	}
+	if item.Team(team) == nil {
+		return nil, models.ErrNotFound(fmt.Sprintf("Team [%s] is not found", team))
+	}
+
+	a.Logger.Debugf("Organization.RemoveTeam: %s -> %s", code, team)
+
+	teams := make([]*models.Team, 0, len(item.Teams))
+	for _, rec := range item.Teams {
+		if rec.Code != team {
+			teams = append(teams, rec)
+		}
+	}
+	item.Teams = teams
+
+	for _, project := range a.Storage.ProjectCRUD().List(item.ID.Hex()) {
+		if project.TeamMember(team) == nil {
+			continue
+		}
+		members := make([]*models.ProjectMember, 0, len(project.Members))
+		for _, member := range project.Members {
+			if member.Team != team {
+				members = append(members, member)
+			}
+		}
+		project.Members = members
+		if _, err := a.Storage.ProjectCRUD().Update(project); err != nil {
+			return nil, models.ErrInternalServer(err.Error())
+		}
+	}
+
+	return a.update(item)
+}
+
+func (a *Organization) update(item *models.Organization) (*models.Organization, error) {
+	resp, err := a.Storage.OrganizationCRUD().Update(item)
+	if err != nil {
+		return nil, models.ErrInternalServer(err.Error())
+	}
+	return resp, nil
+}
+
+// findOrganization returns organization by code or not found error when user is not a member
+func findOrganization(st storage.Storage, user string, code string) (*models.Organization, error) {
+	item := st.OrganizationCRUD().Get(code)
+	if item == nil || item.Member(user) == nil {
+		return nil, models.ErrNotFound(fmt.Sprintf("Organization with code [%s] is not found", code))
+	}
+	return item, nil
+}
+
+func ownersCount(item *models.Organization) int {
+	count := 0
+	for _, member := range item.Members {
+		if member.Role == models.OrgRoleOwner {
+			count++
+		}
+	}
+	return count
+}
+
+func without(values []string, value string) []string {
+	results := make([]string, 0, len(values))
+	for _, v := range values {
+		if v != value {
+			results = append(results, v)
+		}
+	}
+	return results
+}
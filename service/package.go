@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"bitbucket.org/toggly/toggly-server/models"
+	"bitbucket.org/toggly/toggly-server/storage"
+	"github.com/op/go-logging"
+)
+
+// Package Service
+type Package struct {
+	Storage storage.Storage
+	Events  *Events
+	Ctx     context.Context
+	Config  *models.Config
+	Logger  *logging.Logger
+}
+
+// findPackage returns project package by codes or not found error
+func findPackage(st storage.Storage, owner string, project string, code string) (*models.Package, error) {
+	proj, err := findProject(st, owner, project)
+	if err != nil {
+		return nil, err
+	}
+	if !st.PackageCRUD().IsExist(owner, proj.ID, code) {
+		return nil, models.ErrNotFound(fmt.Sprintf("Package with code [%s] is not found", code))
+	}
+	return st.PackageCRUD().Get(owner, proj.ID, code), nil
+}
+
+// Get package by code
+func (a *Package) Get(owner string, project string, code string) (*models.Package, error) {
+	return findPackage(a.Storage, owner, project, code)
+}
+
+// List project packages
+func (a *Package) List(owner string, project string) ([]*models.Package, error) {
+	proj, err := findProject(a.Storage, owner, project)
+	if err != nil {
+		return nil, err
+	}
+	return a.Storage.PackageCRUD().List(owner, proj.ID), nil
+}
+
+// Create package
+func (a *Package) Create(owner string, project string, data models.Package) (*models.Package, error) {
+	if data.Code == "" {
+		return nil, models.ErrBadRequest("Code is invalid")
+	}
+	if data.Name == "" {
+		return nil, models.ErrBadRequest("Name is invalid")
+	}
+
+	proj, err := activeProject(a.Storage, owner, project)
+	if err != nil {
+		return nil, err
+	}
+
+	// fill up default values
+	data.ProjectID = proj.ID
+	data.OwnerID = proj.OwnerID
+	data.RegDate = time.Now()
+
+	a.Logger.Debugf("Package.Create: %+v", data)
+
+	resp, err := a.Storage.PackageCRUD().Create(&data)
+	if err != nil {
+		if storage.IsDuplicateKey(err) {
+			return nil, models.ErrConflict("Code is already exist")
+		}
+		return nil, models.ErrInternalServer(err.Error())
+	}
+
+	a.Events.publish(owner, project, "", models.EventEntityPackage, models.EventActionCreate, resp.Code)
+
+	return resp, nil
+}
+
+// Update package
+func (a *Package) Update(owner string, project string, data models.Package) (*models.Package, error) {
+	if data.Name == "" {
+		return nil, models.ErrBadRequest("Name is invalid")
+	}
+
+	a.Logger.Debugf("Package.Update: %+v", data)
+
+	if _, err := activeProject(a.Storage, owner, project); err != nil {
+		return nil, err
+	}
+	item, err := a.Get(owner, project, data.Code)
+	if err != nil {
+		return nil, err
+	}
+
+	// revalue existing data
+	item.Name = data.Name
+	item.Description = data.Description
+
+	resp, err := a.Storage.PackageCRUD().Update(item)
+	if err != nil {
+		return nil, models.ErrInternalServer(err.Error())
+	}
+
+	a.Events.publish(owner, project, "", models.EventEntityPackage, models.EventActionUpdate, resp.Code)
+
+	return resp, nil
+}
+
+// Delete package, packages with assigned parameters can't be deleted
+func (a *Package) Delete(owner string, project string, code string) error {
+	if _, err := activeProject(a.Storage, owner, project); err != nil {
+		return err
+	}
+	item, err := a.Get(owner, project, code)
+	if err != nil {
+		return err
+	}
+	if a.Storage.ParameterCRUD().IsPackageUsed(owner, item.ProjectID, item.Code) {
+		return models.ErrConflict(fmt.Sprintf("Package [%s] has assigned parameters", code))
+	}
+
+	a.Logger.Debugf("Package.Delete: %+v", item)
+
+	if err := a.Storage.PackageCRUD().Delete(owner, item.ProjectID, item.Code); err != nil {
+		return models.ErrInternalServer(err.Error())
+	}
+
+	a.Events.publish(owner, project, "", models.EventEntityPackage, models.EventActionDelete, code)
+
+	return nil
+}
+
+// Parameters returns package parameters for environment
+func (a *Package) Parameters(owner string, project string, code string, env string) ([]*models.Parameter, error) {
+	pkg, err := a.Get(owner, project, code)
+	if err != nil {
+		return nil, err
+	}
+	environment, err := findEnvironment(a.Storage, owner, project, env)
+	if err != nil {
+		return nil, err
+	}
+	return a.Storage.ParameterCRUD().ListByPackage(owner, environment.ID, pkg.Code), nil
+}
+
+// Values returns package parameter values for environment,
+// object overrides are applied when instance id is given
+func (a *Package) Values(owner string, project string, code string, env string, instanceID string) (map[string]interface{}, error) {
+	if _, err := a.Get(owner, project, code); err != nil {
+		return nil, err
+	}
+	if instanceID != "" {
+		if _, err := findObject(a.Storage, owner, project, instanceID); err != nil {
+			return nil, err
+		}
+	}
+	return resolveValues(a.Storage, owner, project, env, code, instanceID, nil, false)
+}
@@ -0,0 +1,278 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"bitbucket.org/toggly/toggly-server/models"
+	"bitbucket.org/toggly/toggly-server/storage"
+	"github.com/op/go-logging"
+)
+
+// Parameter Service
+type Parameter struct {
+	Storage storage.Storage
+	Events  *Events
+	Ctx     context.Context
+	Config  *models.Config
+	Logger  *logging.Logger
+}
+
+// Get parameter by code
+func (a *Parameter) Get(owner string, project string, env string, code string) (*models.Parameter, error) {
+	environment, err := findEnvironment(a.Storage, owner, project, env)
+	if err != nil {
+		return nil, err
+	}
+	if !a.Storage.ParameterCRUD().IsExist(owner, environment.ID, code) {
+		return nil, models.ErrNotFound(fmt.Sprintf("Parameter with code [%s] is not found", code))
+	}
+	return a.Storage.ParameterCRUD().Get(owner, environment.ID, code), nil
+}
+
+// List environment parameters
+func (a *Parameter) List(owner string, project string, env string) ([]*models.Parameter, error) {
+	environment, err := findEnvironment(a.Storage, owner, project, env)
+	if err != nil {
+		return nil, err
+	}
+	return a.Storage.ParameterCRUD().List(owner, environment.ID), nil
+}
+
+// Create parameter
+func (a *Parameter) Create(owner string, project string, env string, data models.Parameter) (*models.Parameter, error) {
+	resp, err := a.create(owner, project, env, data)
+	if err != nil {
+		return nil, err
+	}
+	a.addVersion(resp, models.VersionActionCreate, 0)
+	return resp, nil
+}
+
+func (a *Parameter) create(owner string, project string, env string, data models.Parameter) (*models.Parameter, error) {
+	if data.Code == "" {
+		return nil, models.ErrBadRequest("Code is invalid")
+	}
+	if err := data.Validate(); err != nil {
+		return nil, err
+	}
+
+	environment, err := activeEnvironment(a.Storage, owner, project, env)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := a.checkPackage(owner, environment, data.Package); err != nil {
+		return nil, err
+	}
+
+	// fill up default values
+	data.ProjectID = environment.ProjectID
+	data.EnvironmentID = environment.ID
+	data.OwnerID = environment.OwnerID
+	data.RegDate = time.Now()
+
+	a.Logger.Debugf("Parameter.Create: %+v", data)
+
+	resp, err := a.Storage.ParameterCRUD().Create(&data)
+	if err != nil {
+		if storage.IsDuplicateKey(err) {
+			return nil, models.ErrConflict("Code is already exist")
+		}
+		return nil, models.ErrInternalServer(err.Error())
+	}
+
+	a.Events.publishParameter(owner, project, env, models.EventActionCreate, resp.Code, resp.Client)
+
+	return resp, nil
+}
+
+// Update parameter
+func (a *Parameter) Update(owner string, project string, env string, data models.Parameter) (*models.Parameter, error) {
+	resp, err := a.update(owner, project, env, data)
+	if err != nil {
+		return nil, err
+	}
+	a.addVersion(resp, models.VersionActionUpdate, 0)
+	return resp, nil
+}
+
+func (a *Parameter) update(owner string, project string, env string, data models.Parameter) (*models.Parameter, error) {
+	if err := data.Validate(); err != nil {
+		return nil, err
+	}
+
+	a.Logger.Debugf("Parameter.Update: %+v", data)
+
+	item, err := a.Get(owner, project, env, data.Code)
+	if err != nil {
+		return nil, err
+	}
+
+	environment, err := activeEnvironment(a.Storage, owner, project, env)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.checkPackage(owner, environment, data.Package); err != nil {
+		return nil, err
+	}
+
+	// clients are notified when parameter is hidden from them too
+	client := item.Client || data.Client
+
+	// revalue existing data
+	item.Package = data.Package
+	item.Description = data.Description
+	item.Type = data.Type
+	item.Value = data.Value
+	item.AllowedValues = data.AllowedValues
+	item.Rules = data.Rules
+	item.Rollout = data.Rollout
+	item.Client = data.Client
+
+	resp, err := a.Storage.ParameterCRUD().Update(item)
+	if err != nil {
+		return nil, models.ErrInternalServer(err.Error())
+	}
+
+	a.Events.publishParameter(owner, project, env, models.EventActionUpdate, resp.Code, client)
+
+	return resp, nil
+}
+
+// Delete parameter
+func (a *Parameter) Delete(owner string, project string, env string, code string) error {
+	if _, err := activeProject(a.Storage, owner, project); err != nil {
+		return err
+	}
+	item, err := a.Get(owner, project, env, code)
+	if err != nil {
+		return err
+	}
+
+	a.Logger.Debugf("Parameter.Delete: %+v", item)
+
+	if err := a.Storage.ParameterCRUD().Delete(owner, item.EnvironmentID, item.Code); err != nil {
+		return models.ErrInternalServer(err.Error())
+	}
+
+	a.Events.publishParameter(owner, project, env, models.EventActionDelete, code, item.Client)
+
+	// deletion is kept in history without snapshot
+	a.addVersion(&models.Parameter{
+		Code:          item.Code,
+		ProjectID:     item.ProjectID,
+		EnvironmentID: item.EnvironmentID,
+		OwnerID:       item.OwnerID,
+	}, models.VersionActionDelete, 0)
+	return nil
+}
+
+// checkPackage verifies that assigned package exists in project
+func (a *Parameter) checkPackage(owner string, environment *models.Environment, pkg string) error {
+	if pkg == "" {
+		return nil
+	}
+	if !a.Storage.PackageCRUD().IsExist(owner, environment.ProjectID, pkg) {
+		return models.ErrBadRequest(fmt.Sprintf("Package [%s] is not found", pkg))
+	}
+	return nil
+}
+
+// Versions returns parameter history, newest version first
+func (a *Parameter) Versions(owner string, project string, env string, code string) ([]*models.ParameterVersion, error) {
+	environment, err := findEnvironment(a.Storage, owner, project, env)
+	if err != nil {
+		return nil, err
+	}
+	recs := a.Storage.VersionCRUD().List(owner, environment.ID, code)
+	if len(recs) == 0 {
+		return nil, models.ErrNotFound(fmt.Sprintf("Parameter with code [%s] has no history", code))
+	}
+	return recs, nil
+}
+
+// Version returns single parameter version
+func (a *Parameter) Version(owner string, project string, env string, code string, version int64) (*models.ParameterVersion, error) {
+	environment, err := findEnvironment(a.Storage, owner, project, env)
+	if err != nil {
+		return nil, err
+	}
+	if !a.Storage.VersionCRUD().IsExist(owner, environment.ID, code, version) {
+		return nil, models.ErrNotFound(fmt.Sprintf("Version [%d] of parameter [%s] is not found", version, code))
+	}
+	return a.Storage.VersionCRUD().Get(owner, environment.ID, code, version), nil
+}
+
+// Diff compares two parameter versions
+func (a *Parameter) Diff(owner string, project string, env string, code string, from int64, to int64) (*models.ParameterVersionDiff, error) {
+	fromVersion, err := a.Version(owner, project, env, code, from)
+	if err != nil {
+		return nil, err
+	}
+	toVersion, err := a.Version(owner, project, env, code, to)
+	if err != nil {
+		return nil, err
+	}
+	changes, err := diff(fromVersion.Parameter, toVersion.Parameter)
+	if err != nil {
+		return nil, models.ErrInternalServer(err.Error())
+	}
+	return &models.ParameterVersionDiff{
+		Code:    code,
+		From:    from,
+		To:      to,
+		Changes: changes,
+	}, nil
+}
+
+// Rollback restores parameter state of given version as new version,
+// deleted parameter is created again
+func (a *Parameter) Rollback(owner string, project string, env string, code string, version int64) (*models.Parameter, error) {
+	item, err := a.Version(owner, project, env, code, version)
+	if err != nil {
+		return nil, err
+	}
+	if item.Parameter == nil {
+		return nil, models.ErrBadRequest(fmt.Sprintf("Version [%d] of parameter [%s] is a deletion", version, code))
+	}
+
+	a.Logger.Debugf("Parameter.Rollback: %+v", item)
+
+	data := *item.Parameter
+	var resp *models.Parameter
+	if a.Storage.ParameterCRUD().IsExist(owner, item.EnvironmentID, code) {
+		resp, err = a.update(owner, project, env, data)
+	} else {
+		resp, err = a.create(owner, project, env, data)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	a.addVersion(resp, models.VersionActionRollback, version)
+
+	return resp, nil
+}
+
+// addVersion appends parameter snapshot to its history following the latest version,
+// parameter change is already saved so history failure is logged only
+func (a *Parameter) addVersion(item *models.Parameter, action string, rollbackOf int64) {
+	rec := &models.ParameterVersion{
+		ProjectID:     item.ProjectID,
+		EnvironmentID: item.EnvironmentID,
+		OwnerID:       item.OwnerID,
+		Code:          item.Code,
+		Version:       a.Storage.VersionCRUD().Last(item.OwnerID, item.EnvironmentID, item.Code) + 1,
+		Action:        action,
+		RollbackOf:    rollbackOf,
+		Time:          time.Now(),
+	}
+	if action != models.VersionActionDelete {
+		rec.Parameter = item
+	}
+	if _, err := a.Storage.VersionCRUD().Create(rec); err != nil {
+		a.Logger.Errorf("Parameter.addVersion: %s", err.Error())
+	}
+}
@@ -0,0 +1,36 @@
+package service
+
+import (
+	"testing"
+
+	"bitbucket.org/toggly/toggly-server/models"
+)
+
+func TestVersionsFollowLatest(t *testing.T) {
+	s := newTenant(t)
+	s.seed(t, ownerA)
+
+	if _, err := s.parameters.Update(ownerA, "p1", "dev", models.Parameter{Code: "f", Type: models.ParameterTypeBool, Value: false}); err != nil {
+		t.Fatalf("Parameter.Update: %s", err.Error())
+	}
+	if err := s.parameters.Delete(ownerA, "p1", "dev", "f"); err != nil {
+		t.Fatalf("Parameter.Delete: %s", err.Error())
+	}
+	if _, err := s.parameters.Rollback(ownerA, "p1", "dev", "f", 2); err != nil {
+		t.Fatalf("Parameter.Rollback: %s", err.Error())
+	}
+
+	versions, err := s.parameters.Versions(ownerA, "p1", "dev", "f")
+	if err != nil {
+		t.Fatalf("Parameter.Versions: %s", err.Error())
+	}
+	actions := []string{models.VersionActionRollback, models.VersionActionDelete, models.VersionActionUpdate, models.VersionActionCreate}
+	if len(versions) != len(actions) {
+		t.Fatalf("%d versions are kept", len(versions))
+	}
+	for i, rec := range versions {
+		if rec.Version != int64(len(actions)-i) || rec.Action != actions[i] {
+			t.Errorf("version %d is %d %s", i, rec.Version, rec.Action)
+		}
+	}
+}
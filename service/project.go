@@ -2,34 +2,35 @@ package service
 
 import (
 	"context"
-	"strings"
+	"fmt"
 
 	"bitbucket.org/toggly/toggly-server/models"
 	"bitbucket.org/toggly/toggly-server/storage"
 	"github.com/op/go-logging"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 // Project Service
 type Project struct {
-	Storage *storage.MongoStorage
+	Storage storage.Storage
 	Ctx     context.Context
 	Config  *models.Config
 	Logger  *logging.Logger
 }
 
 // IsExist checks that project exists by code
-func (a *Project) IsExist(code string) bool {
-	return a.Storage.ProjectCRUD().IsExist(code)
+func (a *Project) IsExist(owner string, code string) bool {
+	return a.Storage.ProjectCRUD().IsExist(owner, code)
 }
 
 // Get project by code
-func (a *Project) Get(code string) *models.Project {
-	return a.Storage.ProjectCRUD().Get(code)
+func (a *Project) Get(owner string, code string) *models.Project {
+	return a.Storage.ProjectCRUD().Get(owner, code)
 }
 
 // List project by code
-func (a *Project) List() []*models.Project {
-	return a.Storage.ProjectCRUD().List()
+func (a *Project) List(owner string) []*models.Project {
+	return a.Storage.ProjectCRUD().List(owner)
 }
 
 // Create project
@@ -45,7 +46,7 @@ func (a *Project) Create(data models.Project) (*models.Project, error) {
 
 	resp, err := a.Storage.ProjectCRUD().Create(&data)
 	if err != nil {
-		if strings.Contains(err.Error(), "E11000") {
+		if storage.IsDuplicateKey(err) {
 			return nil, models.ErrConflict("Code is already exist")
 		}
 		return nil, models.ErrInternalServer(err.Error())
@@ -54,15 +55,21 @@ func (a *Project) Create(data models.Project) (*models.Project, error) {
 	return resp, nil
 }
 
-// Update project
-func (a *Project) Update(data models.Project) (*models.Project, error) {
+// Update project, code, owner and registration date are immutable
+func (a *Project) Update(owner string, code string, data models.Project) (*models.Project, error) {
+	if data.Code != "" && data.Code != code {
+		return nil, models.ErrBadRequest("Code is immutable")
+	}
 	if data.Name == "" {
 		return nil, models.ErrBadRequest("Name is invalid")
 	}
 
 	a.Logger.Debugf("Project.Update: %+v", data)
 
-	item := a.Storage.ProjectCRUD().Get(data.Code)
+	item, err := findProject(a.Storage, owner, code)
+	if err != nil {
+		return nil, err
+	}
 
 	// revalue existing data
 	item.Name = data.Name
@@ -75,3 +82,176 @@ func (a *Project) Update(data models.Project) (*models.Project, error) {
 
 	return resp, nil
 }
+
+// SetStatus archives or restores project
+func (a *Project) SetStatus(owner string, code string, status models.ProjectStatus) (*models.Project, error) {
+	item, err := findProject(a.Storage, owner, code)
+	if err != nil {
+		return nil, err
+	}
+	if item.Status == status {
+		return nil, models.ErrConflict(fmt.Sprintf("Project [%s] is already %s", code, status))
+	}
+
+	a.Logger.Debugf("Project.SetStatus: %s -> %s", code, status)
+
+	item.Status = status
+
+	resp, err := a.Storage.ProjectCRUD().Update(item)
+	if err != nil {
+		return nil, models.ErrInternalServer(err.Error())
+	}
+
+	return resp, nil
+}
+
+// SetMember assigns role to project member, which is either user or organization team,
+// environments limit editor only
+func (a *Project) SetMember(owner string, code string, data models.ProjectMember) (*models.Project, error) {
+	if (data.UserID == "") == (data.Team == "") {
+		return nil, models.ErrBadRequest("Either user or team is required")
+	}
+
+	item, err := findProject(a.Storage, owner, code)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateMember(a.Storage, item, data); err != nil {
+		return nil, err
+	}
+	if data.Team != "" {
+		if org := ownerOrganization(a.Storage, owner); org == nil || org.Team(data.Team) == nil {
+			return nil, models.ErrBadRequest(fmt.Sprintf("Team [%s] is not found", data.Team))
+		}
+	}
+
+	a.Logger.Debugf("Project.SetMember: %s -> %+v", code, data)
+
+	if member := item.Grant(data); member != nil {
+		*member = data
+	} else {
+		item.Members = append(item.Members, &data)
+	}
+
+	resp, err := a.Storage.ProjectCRUD().Update(item)
+	if err != nil {
+		return nil, models.ErrInternalServer(err.Error())
+	}
+
+	return resp, nil
+}
+
+// RemoveMember takes away project access from user or team
+func (a *Project) RemoveMember(owner string, code string, data models.ProjectMember) (*models.Project, error) {
+	item, err := findProject(a.Storage, owner, code)
+	if err != nil {
+		return nil, err
+	}
+	grant := item.Grant(data)
+	if grant == nil {
+		return nil, models.ErrNotFound(fmt.Sprintf("Member [%s%s] is not found", data.UserID, data.Team))
+	}
+
+	a.Logger.Debugf("Project.RemoveMember: %s -> %+v", code, data)
+
+	members := make([]*models.ProjectMember, 0, len(item.Members))
+	for _, member := range item.Members {
+		if member != grant {
+			members = append(members, member)
+		}
+	}
+	item.Members = members
+
+	resp, err := a.Storage.ProjectCRUD().Update(item)
+	if err != nil {
+		return nil, models.ErrInternalServer(err.Error())
+	}
+
+	return resp, nil
+}
+
+// Delete project with all its environments, packages, objects, parameters, their history, change requests,
+// SDK keys and invitations
+func (a *Project) Delete(owner string, code string) error {
+	item, err := findProject(a.Storage, owner, code)
+	if err != nil {
+		return err
+	}
+
+	a.Logger.Debugf("Project.Delete: %+v", item)
+
+	if err := a.Storage.InvitationCRUD().DeleteAll(owner, item.ID); err != nil {
+		return models.ErrInternalServer(err.Error())
+	}
+	if err := a.Storage.SDKKeyCRUD().DeleteAll(owner, item.ID); err != nil {
+		return models.ErrInternalServer(err.Error())
+	}
+	if err := a.Storage.ChangeCRUD().DeleteAll(owner, item.ID); err != nil {
+		return models.ErrInternalServer(err.Error())
+	}
+	if err := a.Storage.VersionCRUD().DeleteAll(owner, item.ID); err != nil {
+		return models.ErrInternalServer(err.Error())
+	}
+	if err := a.Storage.ParameterCRUD().DeleteAll(owner, item.ID); err != nil {
+		return models.ErrInternalServer(err.Error())
+	}
+	if err := a.Storage.ObjectCRUD().DeleteAll(owner, item.ID); err != nil {
+		return models.ErrInternalServer(err.Error())
+	}
+	if err := a.Storage.PackageCRUD().DeleteAll(owner, item.ID); err != nil {
+		return models.ErrInternalServer(err.Error())
+	}
+	if err := a.Storage.EnvironmentCRUD().DeleteAll(owner, item.ID); err != nil {
+		return models.ErrInternalServer(err.Error())
+	}
+	if err := a.Storage.ProjectCRUD().Delete(owner, item.Code); err != nil {
+		return models.ErrInternalServer(err.Error())
+	}
+
+	return nil
+}
+
+// findProject returns project by code or not found error
+func findProject(st storage.Storage, owner string, code string) (*models.Project, error) {
+	if !st.ProjectCRUD().IsExist(owner, code) {
+		return nil, models.ErrNotFound(fmt.Sprintf("Project with code [%s] is not found", code))
+	}
+	return st.ProjectCRUD().Get(owner, code), nil
+}
+
+// activeProject returns project by code, archived projects are read only
+func activeProject(st storage.Storage, owner string, code string) (*models.Project, error) {
+	item, err := findProject(st, owner, code)
+	if err != nil {
+		return nil, err
+	}
+	if item.Status == models.ProjectStatusDisabled {
+		return nil, models.ErrConflict(fmt.Sprintf("Project [%s] is archived", code))
+	}
+	return item, nil
+}
+
+// validateMember checks project role and environments editor is limited to
+func validateMember(st storage.Storage, item *models.Project, data models.ProjectMember) error {
+	if !models.IsRole(data.Role) {
+		return models.ErrBadRequest(fmt.Sprintf("Role [%s] is unknown", data.Role))
+	}
+	if len(data.Environments) > 0 && data.Role != models.RoleEditor {
+		return models.ErrBadRequest("Only editor can be limited to environments")
+	}
+	for _, env := range data.Environments {
+		if !st.EnvironmentCRUD().IsExist(item.OwnerID, item.ID, env) {
+			return models.ErrBadRequest(fmt.Sprintf("Environment with code [%s] is not found", env))
+		}
+	}
+	return nil
+}
+
+// ownerOrganization returns organization owning projects, nil for personal owner
+func ownerOrganization(st storage.Storage, owner string) *models.Organization {
+	id, err := primitive.ObjectIDFromHex(owner)
+	if err != nil {
+		return nil
+	}
+	return st.OrganizationCRUD().Find(id)
+}
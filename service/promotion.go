@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"bitbucket.org/toggly/toggly-server/models"
+	"bitbucket.org/toggly/toggly-server/storage"
+	"github.com/op/go-logging"
+)
+
+// Promotion Service
+type Promotion struct {
+	Storage storage.Storage
+	Events  *Events
+	Ctx     context.Context
+	Config  *models.Config
+	Logger  *logging.Logger
+}
+
+// Preview returns parameter differences to apply from source to target environment
+func (a *Promotion) Preview(owner string, project string, source string, target string) (*models.Promotion, error) {
+	if source == target {
+		return nil, models.ErrBadRequest("Source and target environments are the same")
+	}
+	from, err := findEnvironment(a.Storage, owner, project, source)
+	if err != nil {
+		return nil, err
+	}
+	to, err := findEnvironment(a.Storage, owner, project, target)
+	if err != nil {
+		return nil, err
+	}
+
+	sourceParams := make(map[string]*models.Parameter)
+	for _, param := range a.Storage.ParameterCRUD().List(owner, from.ID) {
+		sourceParams[param.Code] = param
+	}
+	targetParams := make(map[string]*models.Parameter)
+	for _, param := range a.Storage.ParameterCRUD().List(owner, to.ID) {
+		targetParams[param.Code] = param
+	}
+
+	result := &models.Promotion{
+		Source:    from.Code,
+		Target:    to.Code,
+		Protected: to.Protected,
+		Items:     make([]*models.PromotionItem, 0),
+	}
+	for code, param := range sourceParams {
+		item := &models.PromotionItem{
+			Code:   code,
+			Action: models.PromotionActionCreate,
+			Before: targetParams[code],
+			After:  param,
+		}
+		if item.Before != nil {
+			item.Action = models.PromotionActionUpdate
+		}
+		if item.Changes, err = promotionDiff(item.Before, item.After); err != nil {
+			return nil, models.ErrInternalServer(err.Error())
+		}
+		if len(item.Changes) != 0 {
+			result.Items = append(result.Items, item)
+		}
+	}
+	for code, param := range targetParams {
+		if _, ok := sourceParams[code]; ok {
+			continue
+		}
+		item := &models.PromotionItem{
+			Code:   code,
+			Action: models.PromotionActionDelete,
+			Before: param,
+		}
+		if item.Changes, err = promotionDiff(item.Before, nil); err != nil {
+			return nil, models.ErrInternalServer(err.Error())
+		}
+		result.Items = append(result.Items, item)
+	}
+	sort.Slice(result.Items, func(i, j int) bool {
+		return result.Items[i].Code < result.Items[j].Code
+	})
+
+	return result, nil
+}
+
+// Prepare returns preview of promotion with requested parameters only
+func (a *Promotion) Prepare(owner string, project string, source string, target string, req models.PromotionRequest) (*models.Promotion, error) {
+	preview, err := a.Preview(owner, project, source, target)
+	if err != nil {
+		return nil, err
+	}
+	return preview.Select(req)
+}
+
+// Apply promotes selected parameters from source to target environment,
+// protected target requires explicit confirmation
+func (a *Promotion) Apply(owner string, project string, source string, target string, req models.PromotionRequest) (*models.Promotion, error) {
+	prepared, err := a.Prepare(owner, project, source, target, req)
+	if err != nil {
+		return nil, err
+	}
+	if prepared.Protected && !req.Confirm {
+		return nil, models.ErrForbidden(fmt.Sprintf("Environment [%s] is protected, promotion must be confirmed", target))
+	}
+
+	a.Logger.Debugf("Promotion.Apply: %s -> %s %+v", source, target, req)
+
+	return a.ApplyPrepared(owner, project, prepared)
+}
+
+// ApplyPrepared applies prepared items as they are, so approved promotion
+// changes target exactly as it was reviewed even when source has changed since
+func (a *Promotion) ApplyPrepared(owner string, project string, prepared *models.Promotion) (*models.Promotion, error) {
+	if _, err := activeProject(a.Storage, owner, project); err != nil {
+		return nil, err
+	}
+	target := prepared.Target
+	params := &Parameter{
+		Storage: a.Storage,
+		Events:  a.Events,
+		Ctx:     a.Ctx,
+		Config:  a.Config,
+		Logger:  a.Logger,
+	}
+	applied := &models.Promotion{
+		Source:    prepared.Source,
+		Target:    prepared.Target,
+		Protected: prepared.Protected,
+		Items:     make([]*models.PromotionItem, 0),
+	}
+	var err error
+	for _, item := range prepared.Items {
+		// audit compares with target state at the moment of applying
+		item.Before, _ = params.Get(owner, project, target, item.Code)
+		switch item.Action {
+		case models.PromotionActionCreate:
+			item.After, err = params.Create(owner, project, target, promotable(item.After))
+		case models.PromotionActionUpdate:
+			item.After, err = params.Update(owner, project, target, promotable(item.After))
+		case models.PromotionActionDelete:
+			err = params.Delete(owner, project, target, item.Code)
+		}
+		if err != nil {
+			return applied, err
+		}
+		applied.Items = append(applied.Items, item)
+	}
+
+	return applied, nil
+}
+
+// promotable copies parameter configuration without environment bindings
+func promotable(param *models.Parameter) models.Parameter {
+	return models.Parameter{
+		Code:          param.Code,
+		Package:       param.Package,
+		Description:   param.Description,
+		Type:          param.Type,
+		Value:         param.Value,
+		AllowedValues: param.AllowedValues,
+		Rules:         param.Rules,
+		Rollout:       param.Rollout,
+		Client:        param.Client,
+	}
+}
+
+// promotionDiff compares parameter configurations, missing parameter is nil
+func promotionDiff(before *models.Parameter, after *models.Parameter) (map[string]*models.AuditChange, error) {
+	var from, to *models.Parameter
+	if before != nil {
+		param := promotable(before)
+		from = &param
+	}
+	if after != nil {
+		param := promotable(after)
+		to = &param
+	}
+	changes, err := diff(from, to)
+	if err != nil {
+		return nil, err
+	}
+	delete(changes, "reg_date")
+	return changes, nil
+}
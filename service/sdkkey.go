@@ -0,0 +1,142 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"bitbucket.org/toggly/toggly-server/models"
+	"bitbucket.org/toggly/toggly-server/storage"
+	"github.com/op/go-logging"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// SDKKey Service
+type SDKKey struct {
+	Storage storage.Storage
+	Ctx     context.Context
+	Config  *models.Config
+	Logger  *logging.Logger
+}
+
+// List environment keys including the ones in grace period
+func (a *SDKKey) List(owner string, project string, env string) ([]*models.SDKKey, error) {
+	environment, err := findEnvironment(a.Storage, owner, project, env)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	results := make([]*models.SDKKey, 0)
+	for _, rec := range a.Storage.SDKKeyCRUD().List(owner, environment.ID) {
+		if !rec.IsExpired(now) {
+			results = append(results, rec)
+		}
+	}
+	return results, nil
+}
+
+// Create generates new environment key, plain key is available in response only
+func (a *SDKKey) Create(owner string, project string, env string, kind string) (*models.SDKKey, error) {
+	if kind != models.SDKKeyServer && kind != models.SDKKeyClient {
+		return nil, models.ErrBadRequest("Kind is invalid")
+	}
+	environment, err := activeEnvironment(a.Storage, owner, project, env)
+	if err != nil {
+		return nil, err
+	}
+
+	secret := make([]byte, 24)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, models.ErrInternalServer(err.Error())
+	}
+	key := kind + "-" + hex.EncodeToString(secret)
+
+	data := &models.SDKKey{
+		ProjectID:     environment.ProjectID,
+		EnvironmentID: environment.ID,
+		OwnerID:       environment.OwnerID,
+		Project:       project,
+		Environment:   environment.Code,
+		Kind:          kind,
+		Hash:          hashKey(key),
+		Prefix:        key[:len(kind)+9],
+		RegDate:       time.Now(),
+	}
+
+	a.Logger.Debugf("SDKKey.Create: %s %s", data.Prefix, data.Environment)
+
+	resp, err := a.Storage.SDKKeyCRUD().Create(data)
+	if err != nil {
+		return nil, models.ErrInternalServer(err.Error())
+	}
+	resp.Key = key
+
+	return resp, nil
+}
+
+// Rotate creates new key, active keys of the same kind stay valid during grace period
+func (a *SDKKey) Rotate(owner string, project string, env string, kind string, grace time.Duration) (*models.SDKKey, error) {
+	if grace < 0 {
+		return nil, models.ErrBadRequest("Grace period is invalid")
+	}
+	current, err := a.List(owner, project, env)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := a.Create(owner, project, env, kind)
+	if err != nil {
+		return nil, err
+	}
+
+	expires := resp.RegDate.Add(grace)
+	for _, rec := range current {
+		if rec.Kind != kind || (rec.ExpiresAt != nil && rec.ExpiresAt.Before(expires)) {
+			continue
+		}
+		rec.ExpiresAt = &expires
+		if _, err := a.Storage.SDKKeyCRUD().Update(rec); err != nil {
+			return nil, models.ErrInternalServer(err.Error())
+		}
+	}
+
+	return resp, nil
+}
+
+// Delete revokes key immediately
+func (a *SDKKey) Delete(owner string, project string, env string, id string) error {
+	environment, err := findEnvironment(a.Storage, owner, project, env)
+	if err != nil {
+		return err
+	}
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return models.ErrNotFound(fmt.Sprintf("Key [%s] is not found", id))
+	}
+
+	a.Logger.Debugf("SDKKey.Delete: %s", id)
+
+	if err := a.Storage.SDKKeyCRUD().Delete(owner, environment.ID, oid); err != nil {
+		if err == storage.ErrNotFound {
+			return models.ErrNotFound(fmt.Sprintf("Key [%s] is not found", id))
+		}
+		return models.ErrInternalServer(err.Error())
+	}
+	return nil
+}
+
+// Resolve returns active key by its plain value, nil for unknown or expired keys
+func (a *SDKKey) Resolve(key string) *models.SDKKey {
+	rec := a.Storage.SDKKeyCRUD().Find(hashKey(key))
+	if rec == nil || rec.IsExpired(time.Now()) {
+		return nil
+	}
+	return rec
+}
+
+func hashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
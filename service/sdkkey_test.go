@@ -0,0 +1,28 @@
+package service
+
+import (
+	"net/http"
+	"testing"
+
+	"bitbucket.org/toggly/toggly-server/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestDeleteUnknownKey(t *testing.T) {
+	s := newTenant(t)
+	_, keyID := s.seed(t, ownerA)
+
+	for _, id := range []string{primitive.NewObjectID().Hex(), "bad"} {
+		err := s.keys.Delete(ownerA, "p1", "dev", id)
+		if e, ok := err.(*models.ErrStatusedResponse); !ok || e.Code != http.StatusNotFound {
+			t.Errorf("unknown key [%s] is deleted: %v", id, err)
+		}
+	}
+	if err := s.keys.Delete(ownerA, "p1", "dev", keyID); err != nil {
+		t.Fatalf("SDKKey.Delete: %s", err.Error())
+	}
+	err := s.keys.Delete(ownerA, "p1", "dev", keyID)
+	if e, ok := err.(*models.ErrStatusedResponse); !ok || e.Code != http.StatusNotFound {
+		t.Errorf("deleted key is deleted again: %v", err)
+	}
+}
@@ -0,0 +1,233 @@
+package service
+
+import (
+	"net/http"
+	"testing"
+
+	"bitbucket.org/toggly/toggly-server/models"
+	"bitbucket.org/toggly/toggly-server/storage"
+	"github.com/op/go-logging"
+)
+
+const (
+	ownerA = "owner-a"
+	ownerB = "owner-b"
+)
+
+// tenant keeps services sharing one embedded storage
+type tenant struct {
+	projects     *Project
+	environments *Environment
+	packages     *Package
+	parameters   *Parameter
+	objects      *Object
+	keys         *SDKKey
+	changes      *Change
+	evaluation   *Evaluation
+}
+
+func newTenant(t *testing.T) *tenant {
+	st, err := storage.NewEmbeddedStorage(&models.Storage{Driver: storage.DriverEmbedded, Connection: ":memory:"})
+	if err != nil {
+		t.Fatalf("embedded storage: %s", err.Error())
+	}
+	log := logging.MustGetLogger("test")
+	return &tenant{
+		projects:     &Project{Storage: st, Logger: log},
+		environments: &Environment{Storage: st, Logger: log},
+		packages:     &Package{Storage: st, Logger: log},
+		parameters:   &Parameter{Storage: st, Logger: log},
+		objects:      &Object{Storage: st, Logger: log},
+		keys:         &SDKKey{Storage: st, Logger: log},
+		changes:      &Change{Storage: st, Logger: log},
+		evaluation:   &Evaluation{Storage: st, Logger: log},
+	}
+}
+
+// seed creates project p1 of owner with every kind of nested entity,
+// id of pending change request and SDK key are returned
+func (s *tenant) seed(t *testing.T, owner string) (string, string) {
+	must := func(err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatalf("seed %s: %s", owner, err.Error())
+		}
+	}
+	_, err := s.projects.Create(models.Project{Code: "p1", Name: "P1", OwnerID: owner, Status: models.ProjectStatusActive})
+	must(err)
+	_, err = s.environments.Create(owner, "p1", models.Environment{Code: "dev"})
+	must(err)
+	_, err = s.environments.Create(owner, "p1", models.Environment{Code: "prod", Protected: true})
+	must(err)
+	_, err = s.packages.Create(owner, "p1", models.Package{Code: "web", Name: "Web"})
+	must(err)
+	_, err = s.parameters.Create(owner, "p1", "dev", models.Parameter{Code: "f", Package: "web", Type: models.ParameterTypeBool, Value: true})
+	must(err)
+	_, err = s.parameters.Create(owner, "p1", "prod", models.Parameter{Code: "f", Type: models.ParameterTypeBool, Value: true})
+	must(err)
+	_, err = s.objects.Create(owner, "p1", models.Object{InstanceID: "u1", Name: "U1"})
+	must(err)
+	_, err = s.objects.SetOverride(owner, "p1", "u1", "dev", "f", false)
+	must(err)
+	key, err := s.keys.Create(owner, "p1", "dev", models.SDKKeyServer)
+	must(err)
+	change, err := s.changes.Create(owner, owner, "p1", "prod", models.ChangeRequest{
+		Entity:    models.ChangeEntityParameter,
+		Action:    models.ChangeActionUpdate,
+		Code:      "f",
+		Parameter: &models.Parameter{Code: "f", Type: models.ParameterTypeBool, Value: false},
+	})
+	must(err)
+	return change.ID.Hex(), key.ID.Hex()
+}
+
+func expectNotFound(t *testing.T, name string, err error) {
+	t.Helper()
+	if err == nil {
+		t.Errorf("%s: entity of another owner is available", name)
+		return
+	}
+	if e, ok := err.(*models.ErrStatusedResponse); !ok || e.Code != http.StatusNotFound {
+		t.Errorf("%s: expected not found, got %s", name, err.Error())
+	}
+}
+
+func TestOtherOwnerCantReadEntities(t *testing.T) {
+	s := newTenant(t)
+	changeID, _ := s.seed(t, ownerA)
+
+	if s.projects.IsExist(ownerB, "p1") {
+		t.Error("Project.IsExist: project of another owner exists")
+	}
+	if p := s.projects.Get(ownerB, "p1"); p.Code != "" {
+		t.Errorf("Project.Get: project of another owner is returned: %+v", p)
+	}
+	if recs := s.projects.List(ownerB); len(recs) != 0 {
+		t.Errorf("Project.List: %d projects of another owner are listed", len(recs))
+	}
+	if s.environments.IsExist(ownerB, "p1", "dev") {
+		t.Error("Environment.IsExist: environment of another owner exists")
+	}
+
+	_, err := s.environments.Get(ownerB, "p1", "dev")
+	expectNotFound(t, "Environment.Get", err)
+	_, err = s.environments.List(ownerB, "p1")
+	expectNotFound(t, "Environment.List", err)
+	_, err = s.packages.Get(ownerB, "p1", "web")
+	expectNotFound(t, "Package.Get", err)
+	_, err = s.packages.List(ownerB, "p1")
+	expectNotFound(t, "Package.List", err)
+	_, err = s.packages.Parameters(ownerB, "p1", "web", "dev")
+	expectNotFound(t, "Package.Parameters", err)
+	_, err = s.parameters.Get(ownerB, "p1", "dev", "f")
+	expectNotFound(t, "Parameter.Get", err)
+	_, err = s.parameters.List(ownerB, "p1", "dev")
+	expectNotFound(t, "Parameter.List", err)
+	_, err = s.parameters.Versions(ownerB, "p1", "dev", "f")
+	expectNotFound(t, "Parameter.Versions", err)
+	_, err = s.parameters.Version(ownerB, "p1", "dev", "f", 1)
+	expectNotFound(t, "Parameter.Version", err)
+	_, err = s.objects.Get(ownerB, "p1", "u1")
+	expectNotFound(t, "Object.Get", err)
+	_, err = s.objects.List(ownerB, "p1")
+	expectNotFound(t, "Object.List", err)
+	_, err = s.objects.Values(ownerB, "p1", "u1", "dev")
+	expectNotFound(t, "Object.Values", err)
+	_, err = s.keys.List(ownerB, "p1", "dev")
+	expectNotFound(t, "SDKKey.List", err)
+	_, err = s.changes.List(ownerB, "p1", "", "")
+	expectNotFound(t, "Change.List", err)
+	_, err = s.changes.Get(ownerB, "p1", changeID)
+	expectNotFound(t, "Change.Get", err)
+	_, err = s.evaluation.Values(ownerB, "p1", "dev", "", "u1", nil)
+	expectNotFound(t, "Evaluation.Values", err)
+}
+
+func TestOtherOwnerCantChangeEntities(t *testing.T) {
+	s := newTenant(t)
+	changeID, keyID := s.seed(t, ownerA)
+
+	_, err := s.projects.Update(ownerB, "p1", models.Project{Name: "Stolen"})
+	expectNotFound(t, "Project.Update", err)
+	_, err = s.projects.SetStatus(ownerB, "p1", models.ProjectStatusDisabled)
+	expectNotFound(t, "Project.SetStatus", err)
+	_, err = s.projects.SetMember(ownerB, "p1", models.ProjectMember{UserID: ownerB, Role: models.RoleAdmin})
+	expectNotFound(t, "Project.SetMember", err)
+	_, err = s.environments.Create(ownerB, "p1", models.Environment{Code: "qa"})
+	expectNotFound(t, "Environment.Create", err)
+	_, err = s.environments.Update(ownerB, "p1", models.Environment{Code: "dev", Description: "stolen"})
+	expectNotFound(t, "Environment.Update", err)
+	expectNotFound(t, "Environment.Delete", s.environments.Delete(ownerB, "p1", "dev"))
+	_, err = s.packages.Update(ownerB, "p1", models.Package{Code: "web", Name: "Stolen"})
+	expectNotFound(t, "Package.Update", err)
+	expectNotFound(t, "Package.Delete", s.packages.Delete(ownerB, "p1", "web"))
+	_, err = s.parameters.Create(ownerB, "p1", "dev", models.Parameter{Code: "g", Type: models.ParameterTypeBool, Value: true})
+	expectNotFound(t, "Parameter.Create", err)
+	_, err = s.parameters.Update(ownerB, "p1", "dev", models.Parameter{Code: "f", Type: models.ParameterTypeBool, Value: false})
+	expectNotFound(t, "Parameter.Update", err)
+	_, err = s.parameters.Rollback(ownerB, "p1", "dev", "f", 1)
+	expectNotFound(t, "Parameter.Rollback", err)
+	expectNotFound(t, "Parameter.Delete", s.parameters.Delete(ownerB, "p1", "dev", "f"))
+	_, err = s.objects.Update(ownerB, "p1", models.Object{InstanceID: "u1", Name: "Stolen"})
+	expectNotFound(t, "Object.Update", err)
+	_, err = s.objects.SetOverride(ownerB, "p1", "u1", "dev", "f", true)
+	expectNotFound(t, "Object.SetOverride", err)
+	_, err = s.objects.RemoveOverride(ownerB, "p1", "u1", "dev", "f")
+	expectNotFound(t, "Object.RemoveOverride", err)
+	expectNotFound(t, "Object.Delete", s.objects.Delete(ownerB, "p1", "u1"))
+	_, err = s.keys.Create(ownerB, "p1", "dev", models.SDKKeyServer)
+	expectNotFound(t, "SDKKey.Create", err)
+	expectNotFound(t, "SDKKey.Delete", s.keys.Delete(ownerB, "p1", "dev", keyID))
+	_, err = s.changes.Approve(ownerB, ownerB, "p1", changeID)
+	expectNotFound(t, "Change.Approve", err)
+	_, _, err = s.changes.Apply(ownerB, "p1", changeID)
+	expectNotFound(t, "Change.Apply", err)
+	expectNotFound(t, "Project.Delete", s.projects.Delete(ownerB, "p1"))
+
+	// nothing of owner A is touched
+	if p := s.projects.Get(ownerA, "p1"); p.Name != "P1" || p.Status != models.ProjectStatusActive || len(p.Members) != 0 {
+		t.Errorf("project is changed: %+v", p)
+	}
+	param, err := s.parameters.Get(ownerA, "p1", "dev", "f")
+	if err != nil || param.Value != true {
+		t.Errorf("parameter is changed: %+v %v", param, err)
+	}
+	obj, err := s.objects.Get(ownerA, "p1", "u1")
+	if err != nil || obj.Name != "U1" || obj.Override("dev", "f") == nil {
+		t.Errorf("object is changed: %+v %v", obj, err)
+	}
+	if keys, err := s.keys.List(ownerA, "p1", "dev"); err != nil || len(keys) != 1 {
+		t.Errorf("SDK keys are changed: %d %v", len(keys), err)
+	}
+	if change, err := s.changes.Get(ownerA, "p1", changeID); err != nil || change.Status != models.ChangeStatusPending {
+		t.Errorf("change request is changed: %+v %v", change, err)
+	}
+}
+
+func TestOwnersShareCodes(t *testing.T) {
+	s := newTenant(t)
+	s.seed(t, ownerA)
+	s.seed(t, ownerB)
+
+	if _, err := s.parameters.Update(ownerB, "p1", "dev", models.Parameter{Code: "f", Package: "web", Type: models.ParameterTypeBool, Value: false}); err != nil {
+		t.Fatalf("Parameter.Update: %s", err.Error())
+	}
+	if err := s.environments.Delete(ownerB, "p1", "dev"); err != nil {
+		t.Fatalf("Environment.Delete: %s", err.Error())
+	}
+
+	values, err := s.evaluation.Values(ownerA, "p1", "dev", "", "", nil)
+	if err != nil {
+		t.Fatalf("Evaluation.Values: %s", err.Error())
+	}
+	if values["f"] != true {
+		t.Errorf("value of owner A is %v", values["f"])
+	}
+	versions, err := s.parameters.Versions(ownerA, "p1", "dev", "f")
+	if err != nil || len(versions) != 1 {
+		t.Errorf("history of owner A has %d versions: %v", len(versions), err)
+	}
+	if projects := s.projects.List(ownerA); len(projects) != 1 || projects[0].OwnerID != ownerA {
+		t.Errorf("owner A lists %d projects", len(projects))
+	}
+}
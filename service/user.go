@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"bitbucket.org/toggly/toggly-server/models"
+	"bitbucket.org/toggly/toggly-server/storage"
+	"github.com/op/go-logging"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// minPasswordLength for registration
+const minPasswordLength = 8
+
+// dummyHash is compared for unknown emails, so response time doesn't reveal registered ones
+var dummyHash, _ = bcrypt.GenerateFromPassword([]byte("toggly"), bcrypt.DefaultCost)
+
+// User Service
+type User struct {
+	Storage storage.Storage
+	Ctx     context.Context
+	Config  *models.Config
+	Logger  *logging.Logger
+}
+
+// Get user by id
+func (a *User) Get(id string) (*models.User, error) {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, models.ErrNotFound("User is not found")
+	}
+	user := a.Storage.UserCRUD().Get(oid)
+	if user == nil {
+		return nil, models.ErrNotFound("User is not found")
+	}
+	return user, nil
+}
+
+// Register user account, password is stored as bcrypt hash
+func (a *User) Register(data models.Credentials) (*models.User, error) {
+	email := normalizeEmail(data.Email)
+	if !strings.Contains(email, "@") {
+		return nil, models.ErrBadRequest("Email is invalid")
+	}
+	if len(data.Password) < minPasswordLength {
+		return nil, models.ErrBadRequest("Password is too short")
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(data.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, models.ErrInternalServer(err.Error())
+	}
+
+	a.Logger.Debugf("User.Register: %s", email)
+
+	resp, err := a.Storage.UserCRUD().Create(&models.User{
+		Email:        email,
+		Name:         data.Name,
+		PasswordHash: string(hash),
+		RegDate:      time.Now(),
+	})
+	if err != nil {
+		if storage.IsDuplicateKey(err) {
+			return nil, models.ErrConflict("Email is already registered")
+		}
+		return nil, models.ErrInternalServer(err.Error())
+	}
+
+	return resp, nil
+}
+
+// Login checks user credentials
+func (a *User) Login(data models.Credentials) (*models.User, error) {
+	user := a.Storage.UserCRUD().FindByEmail(normalizeEmail(data.Email))
+	if user == nil {
+		bcrypt.CompareHashAndPassword(dummyHash, []byte(data.Password))
+		return nil, models.ErrUnauthorized("Email or password is invalid")
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(data.Password)); err != nil {
+		return nil, models.ErrUnauthorized("Email or password is invalid")
+	}
+	return user, nil
+}
+
+// normalizeEmail makes emails comparable
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
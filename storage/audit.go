@@ -0,0 +1,86 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"bitbucket.org/toggly/toggly-server/models"
+	dbStore "github.com/nodely/go-mongo-store"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/x/bsonx"
+)
+
+type mgoAudit struct {
+	Storage *dbStore.DbStorage
+	CRUD    dbStore.CRUD
+}
+
+func (a *mgoAudit) List(owner string, filter *models.AuditFilter) []*models.AuditRecord {
+	results := make([]*models.AuditRecord, 0)
+	query := bson.M{"owner_id": owner}
+	for key, value := range map[string]string{
+		"entity":      filter.Entity,
+		"action":      filter.Action,
+		"project":     filter.Project,
+		"environment": filter.Environment,
+		"code":        filter.Code,
+		"instance":    filter.Instance,
+		"request_id":  filter.RequestID,
+		"user":        filter.User,
+	} {
+		if value != "" {
+			query[key] = value
+		}
+	}
+	period := bson.M{}
+	if !filter.From.IsZero() {
+		period["$gte"] = filter.From
+	}
+	if !filter.To.IsZero() {
+		period["$lt"] = filter.To
+	}
+	if len(period) > 0 {
+		query["time"] = period
+	}
+	opts := options.Find().SetSort(bson.D{{"time", -1}})
+	if filter.Limit > 0 {
+		opts.SetLimit(filter.Limit)
+	}
+	cursor, err := a.CRUD.Find(query, opts)
+	if err != nil {
+		fmt.Println(err.Error())
+		return results
+	}
+	for cursor.Next(context.TODO()) {
+		var rec models.AuditRecord
+		cursor.Decode(&rec)
+		results = append(results, &rec)
+	}
+	return results
+}
+
+func (a *mgoAudit) Create(data *models.AuditRecord) (*models.AuditRecord, error) {
+	// check index
+	if err := a.ensureIndexes(); err != nil {
+		return nil, err
+	}
+
+	ins, err := a.CRUD.Insert(data)
+	if err != nil {
+		return nil, err
+	}
+	data.ID = ins[0].(primitive.ObjectID)
+	return data, nil
+}
+
+func (a *mgoAudit) ensureIndexes() error {
+	return a.CRUD.EnsureIndexesRaw(mongo.IndexModel{
+		Keys: bsonx.Doc{
+			{Key: "owner_id", Value: bsonx.Int32(1)},
+			{Key: "time", Value: bsonx.Int32(-1)},
+		},
+	})
+}
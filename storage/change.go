@@ -0,0 +1,107 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"bitbucket.org/toggly/toggly-server/models"
+	dbStore "github.com/nodely/go-mongo-store"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/x/bsonx"
+)
+
+type mgoChange struct {
+	Storage *dbStore.DbStorage
+	CRUD    dbStore.CRUD
+}
+
+func (a *mgoChange) List(owner string, projectID primitive.ObjectID) []*models.ChangeRequest {
+	results := make([]*models.ChangeRequest, 0)
+	cursor, err := a.CRUD.Find(bson.M{"owner_id": owner, "project_id": projectID}, options.Find().SetSort(bson.D{{"_id", -1}}))
+	if err != nil {
+		fmt.Println(err.Error())
+		return results
+	}
+	for cursor.Next(context.TODO()) {
+		var rec models.ChangeRequest
+		cursor.Decode(&rec)
+		results = append(results, &rec)
+	}
+	return results
+}
+
+func (a *mgoChange) Get(owner string, projectID primitive.ObjectID, id primitive.ObjectID) *models.ChangeRequest {
+	var data models.ChangeRequest
+	a.CRUD.FindOne(bson.M{"owner_id": owner, "project_id": projectID, "_id": id}).Decode(&data)
+	return &data
+}
+
+func (a *mgoChange) Create(data *models.ChangeRequest) (*models.ChangeRequest, error) {
+	// check index
+	if err := a.ensureIndexes(); err != nil {
+		return nil, err
+	}
+
+	ins, err := a.CRUD.Insert(data)
+	if err != nil {
+		return nil, err
+	}
+	data.ID = ins[0].(primitive.ObjectID)
+	return data, nil
+}
+
+func (a *mgoChange) Update(data *models.ChangeRequest) (*models.ChangeRequest, error) {
+	err := a.CRUD.SaveItem(data.ID, data)
+	return data, err
+}
+
+func (a *mgoChange) DeleteAll(owner string, projectID primitive.ObjectID) error {
+	cursor, err := a.CRUD.Find(bson.M{"owner_id": owner, "project_id": projectID})
+	if err != nil {
+		return err
+	}
+	for cursor.Next(context.TODO()) {
+		var rec models.ChangeRequest
+		if err := cursor.Decode(&rec); err != nil {
+			return err
+		}
+		if err := a.CRUD.DeleteItem(rec.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (a *mgoChange) DeleteByEnvironment(owner string, projectID primitive.ObjectID, envID primitive.ObjectID) error {
+	cursor, err := a.CRUD.Find(bson.M{"owner_id": owner, "project_id": projectID, "env_id": envID})
+	if err != nil {
+		return err
+	}
+	for cursor.Next(context.TODO()) {
+		var rec models.ChangeRequest
+		if err := cursor.Decode(&rec); err != nil {
+			return err
+		}
+		if err := a.CRUD.DeleteItem(rec.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (a *mgoChange) IsExist(owner string, projectID primitive.ObjectID, id primitive.ObjectID) bool {
+	return a.CRUD.Count(bson.M{"owner_id": owner, "project_id": projectID, "_id": id}) != 0
+}
+
+func (a *mgoChange) ensureIndexes() error {
+	return a.CRUD.EnsureIndexesRaw(mongo.IndexModel{
+		Keys: bsonx.Doc{
+			{Key: "owner_id", Value: bsonx.Int32(1)},
+			{Key: "project_id", Value: bsonx.Int32(1)},
+			{Key: "status", Value: bsonx.Int32(1)},
+		},
+	})
+}
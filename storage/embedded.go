@@ -0,0 +1,196 @@
+package storage
+
+import (
+	"strings"
+
+	"bitbucket.org/toggly/toggly-server/models"
+	"github.com/tidwall/buntdb"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// DriverEmbedded storage driver name
+const DriverEmbedded = "embedded"
+
+func init() {
+	Register(DriverEmbedded, NewEmbeddedStorage)
+}
+
+// EmbeddedStorage struct, single file storage based on buntdb
+type EmbeddedStorage struct {
+	DB *buntdb.DB
+}
+
+// NewEmbeddedStorage opens embedded storage file, connection is a file path or ":memory:"
+func NewEmbeddedStorage(cfg *models.Storage) (Storage, error) {
+	db, err := buntdb.Open(cfg.Connection)
+	if err != nil {
+		return nil, err
+	}
+	return &EmbeddedStorage{DB: db}, nil
+}
+
+// ProjectCRUD func
+func (db *EmbeddedStorage) ProjectCRUD() Project {
+	return &buntProject{buntCollection{DB: db.DB, Prefix: "project"}}
+}
+
+// EnvironmentCRUD func
+func (db *EmbeddedStorage) EnvironmentCRUD() Environment {
+	return &buntEnvironment{buntCollection{DB: db.DB, Prefix: "env"}}
+}
+
+// PackageCRUD func
+func (db *EmbeddedStorage) PackageCRUD() Package {
+	return &buntPackage{buntCollection{DB: db.DB, Prefix: "package"}}
+}
+
+// ObjectCRUD func
+func (db *EmbeddedStorage) ObjectCRUD() Object {
+	return &buntObject{buntCollection{DB: db.DB, Prefix: "object"}}
+}
+
+// ParameterCRUD func
+func (db *EmbeddedStorage) ParameterCRUD() Parameter {
+	return &buntParameter{buntCollection{DB: db.DB, Prefix: "param"}}
+}
+
+// AuditCRUD func
+func (db *EmbeddedStorage) AuditCRUD() Audit {
+	return &buntAudit{buntCollection{DB: db.DB, Prefix: "audit"}}
+}
+
+// VersionCRUD func
+func (db *EmbeddedStorage) VersionCRUD() Version {
+	return &buntVersion{buntCollection{DB: db.DB, Prefix: "version"}}
+}
+
+// ChangeCRUD func
+func (db *EmbeddedStorage) ChangeCRUD() Change {
+	return &buntChange{buntCollection{DB: db.DB, Prefix: "change"}}
+}
+
+// SDKKeyCRUD func
+func (db *EmbeddedStorage) SDKKeyCRUD() SDKKey {
+	return &buntSDKKey{buntCollection{DB: db.DB, Prefix: "sdkkey"}}
+}
+
+// UserCRUD func
+func (db *EmbeddedStorage) UserCRUD() User {
+	return &buntUser{buntCollection{DB: db.DB, Prefix: "user"}}
+}
+
+// OrganizationCRUD func
+func (db *EmbeddedStorage) OrganizationCRUD() Organization {
+	return &buntOrganization{buntCollection{DB: db.DB, Prefix: "org"}}
+}
+
+// InvitationCRUD func
+func (db *EmbeddedStorage) InvitationCRUD() Invitation {
+	return &buntInvitation{buntCollection{DB: db.DB, Prefix: "invitation"}}
+}
+
+// buntCollection keeps bson encoded records under prefixed keys,
+// key uniqueness works as unique index
+type buntCollection struct {
+	DB     *buntdb.DB
+	Prefix string
+}
+
+// keyEscaper escapes key separator and pattern wildcards in key parts
+var keyEscaper = strings.NewReplacer("%", "%25", ":", "%3A", "*", "%2A", "?", "%3F", "\\", "%5C")
+
+// key builds record key, every part is escaped
+func (c *buntCollection) key(parts ...string) string {
+	escaped := make([]string, 0, len(parts)+1)
+	escaped = append(escaped, c.Prefix)
+	for _, part := range parts {
+		escaped = append(escaped, keyEscaper.Replace(part))
+	}
+	return strings.Join(escaped, ":")
+}
+
+// pattern builds pattern matching every record key under given parts
+func (c *buntCollection) pattern(parts ...string) string {
+	return c.key(parts...) + ":*"
+}
+
+// find passes every record matching key pattern to decode func in key order
+func (c *buntCollection) find(pattern string, decode func(data []byte) error) error {
+	return c.DB.View(func(tx *buntdb.Tx) error {
+		var err error
+		tx.AscendKeys(pattern, func(key, value string) bool {
+			err = decode([]byte(value))
+			return err == nil
+		})
+		return err
+	})
+}
+
+// findOne decodes first record matching key pattern
+func (c *buntCollection) findOne(pattern string, v interface{}) bool {
+	found := false
+	c.find(pattern, func(data []byte) error {
+		found = bson.Unmarshal(data, v) == nil
+		return buntdb.ErrNotFound
+	})
+	return found
+}
+
+func (c *buntCollection) count(pattern string) int {
+	count := 0
+	c.DB.View(func(tx *buntdb.Tx) error {
+		return tx.AscendKeys(pattern, func(key, value string) bool {
+			count++
+			return true
+		})
+	})
+	return count
+}
+
+func (c *buntCollection) insert(key string, v interface{}) error {
+	data, err := bson.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return c.DB.Update(func(tx *buntdb.Tx) error {
+		if _, err := tx.Get(key); err != buntdb.ErrNotFound {
+			if err != nil {
+				return err
+			}
+			return ErrDuplicateKey
+		}
+		_, _, err := tx.Set(key, string(data), nil)
+		return err
+	})
+}
+
+func (c *buntCollection) save(key string, v interface{}) error {
+	data, err := bson.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return c.DB.Update(func(tx *buntdb.Tx) error {
+		_, _, err := tx.Set(key, string(data), nil)
+		return err
+	})
+}
+
+// remove deletes all records matching key pattern
+func (c *buntCollection) remove(pattern string) error {
+	return c.DB.Update(func(tx *buntdb.Tx) error {
+		keys := make([]string, 0)
+		err := tx.AscendKeys(pattern, func(key, value string) bool {
+			keys = append(keys, key)
+			return true
+		})
+		if err != nil {
+			return err
+		}
+		for _, key := range keys {
+			if _, err := tx.Delete(key); err != nil && err != buntdb.ErrNotFound {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,49 @@
+package storage
+
+import (
+	"fmt"
+
+	"bitbucket.org/toggly/toggly-server/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// buntAudit keeps records under owner:id keys, object ids keep them in time order
+type buntAudit struct {
+	buntCollection
+}
+
+func (a *buntAudit) List(owner string, filter *models.AuditFilter) []*models.AuditRecord {
+	results := make([]*models.AuditRecord, 0)
+	err := a.find(a.pattern(owner), func(data []byte) error {
+		var rec models.AuditRecord
+		if err := bson.Unmarshal(data, &rec); err != nil {
+			return err
+		}
+		if filter.Match(&rec) {
+			results = append(results, &rec)
+		}
+		return nil
+	})
+	if err != nil {
+		fmt.Println(err.Error())
+	}
+	// newest first
+	for i, j := 0, len(results)-1; i < j; i, j = i+1, j-1 {
+		results[i], results[j] = results[j], results[i]
+	}
+	if filter.Limit > 0 && int64(len(results)) > filter.Limit {
+		results = results[:filter.Limit]
+	}
+	return results
+}
+
+func (a *buntAudit) Create(data *models.AuditRecord) (*models.AuditRecord, error) {
+	if data.ID == primitive.NilObjectID {
+		data.ID = primitive.NewObjectID()
+	}
+	if err := a.insert(a.key(data.OwnerID, data.ID.Hex()), data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
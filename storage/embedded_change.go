@@ -0,0 +1,75 @@
+package storage
+
+import (
+	"fmt"
+
+	"bitbucket.org/toggly/toggly-server/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// buntChange keeps change requests under owner:project:id keys
+type buntChange struct {
+	buntCollection
+}
+
+func (a *buntChange) List(owner string, projectID primitive.ObjectID) []*models.ChangeRequest {
+	results := make([]*models.ChangeRequest, 0)
+	err := a.find(a.pattern(owner, projectID.Hex()), func(data []byte) error {
+		var rec models.ChangeRequest
+		if err := bson.Unmarshal(data, &rec); err != nil {
+			return err
+		}
+		results = append(results, &rec)
+		return nil
+	})
+	if err != nil {
+		fmt.Println(err.Error())
+	}
+	// newest first
+	for i, j := 0, len(results)-1; i < j; i, j = i+1, j-1 {
+		results[i], results[j] = results[j], results[i]
+	}
+	return results
+}
+
+func (a *buntChange) Get(owner string, projectID primitive.ObjectID, id primitive.ObjectID) *models.ChangeRequest {
+	var data models.ChangeRequest
+	a.findOne(a.key(owner, projectID.Hex(), id.Hex()), &data)
+	return &data
+}
+
+func (a *buntChange) Create(data *models.ChangeRequest) (*models.ChangeRequest, error) {
+	if data.ID == primitive.NilObjectID {
+		data.ID = primitive.NewObjectID()
+	}
+	if err := a.insert(a.key(data.OwnerID, data.ProjectID.Hex(), data.ID.Hex()), data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func (a *buntChange) Update(data *models.ChangeRequest) (*models.ChangeRequest, error) {
+	err := a.save(a.key(data.OwnerID, data.ProjectID.Hex(), data.ID.Hex()), data)
+	return data, err
+}
+
+func (a *buntChange) DeleteAll(owner string, projectID primitive.ObjectID) error {
+	return a.remove(a.pattern(owner, projectID.Hex()))
+}
+
+func (a *buntChange) DeleteByEnvironment(owner string, projectID primitive.ObjectID, envID primitive.ObjectID) error {
+	for _, rec := range a.List(owner, projectID) {
+		if rec.EnvironmentID != envID {
+			continue
+		}
+		if err := a.remove(a.key(owner, projectID.Hex(), rec.ID.Hex())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (a *buntChange) IsExist(owner string, projectID primitive.ObjectID, id primitive.ObjectID) bool {
+	return a.count(a.key(owner, projectID.Hex(), id.Hex())) != 0
+}
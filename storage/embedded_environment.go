@@ -0,0 +1,62 @@
+package storage
+
+import (
+	"fmt"
+
+	"bitbucket.org/toggly/toggly-server/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type buntEnvironment struct {
+	buntCollection
+}
+
+func (a *buntEnvironment) List(owner string, projectID primitive.ObjectID) []*models.Environment {
+	results := make([]*models.Environment, 0)
+	err := a.find(a.pattern(owner, projectID.Hex()), func(data []byte) error {
+		var rec models.Environment
+		if err := bson.Unmarshal(data, &rec); err != nil {
+			return err
+		}
+		results = append(results, &rec)
+		return nil
+	})
+	if err != nil {
+		fmt.Println(err.Error())
+	}
+	return results
+}
+
+func (a *buntEnvironment) Get(owner string, projectID primitive.ObjectID, code string) *models.Environment {
+	var data models.Environment
+	a.findOne(a.key(owner, projectID.Hex(), code), &data)
+	return &data
+}
+
+func (a *buntEnvironment) Create(data *models.Environment) (*models.Environment, error) {
+	if data.ID == primitive.NilObjectID {
+		data.ID = primitive.NewObjectID()
+	}
+	if err := a.insert(a.key(data.OwnerID, data.ProjectID.Hex(), data.Code), data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func (a *buntEnvironment) Update(data *models.Environment) (*models.Environment, error) {
+	err := a.save(a.key(data.OwnerID, data.ProjectID.Hex(), data.Code), data)
+	return data, err
+}
+
+func (a *buntEnvironment) Delete(owner string, projectID primitive.ObjectID, code string) error {
+	return a.remove(a.key(owner, projectID.Hex(), code))
+}
+
+func (a *buntEnvironment) DeleteAll(owner string, projectID primitive.ObjectID) error {
+	return a.remove(a.pattern(owner, projectID.Hex()))
+}
+
+func (a *buntEnvironment) IsExist(owner string, projectID primitive.ObjectID, code string) bool {
+	return a.count(a.key(owner, projectID.Hex(), code)) != 0
+}
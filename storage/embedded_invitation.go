@@ -0,0 +1,86 @@
+package storage
+
+import (
+	"fmt"
+	"sort"
+
+	"bitbucket.org/toggly/toggly-server/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// buntInvitation keeps invitations under token hashes, so lookup by token doesn't need owner
+type buntInvitation struct {
+	buntCollection
+}
+
+// list returns matching invitations, newest first
+func (a *buntInvitation) list(filter func(rec *models.Invitation) bool) []*models.Invitation {
+	results := make([]*models.Invitation, 0)
+	err := a.find(a.pattern(), func(data []byte) error {
+		var rec models.Invitation
+		if err := bson.Unmarshal(data, &rec); err != nil {
+			return err
+		}
+		if filter(&rec) {
+			results = append(results, &rec)
+		}
+		return nil
+	})
+	if err != nil {
+		fmt.Println(err.Error())
+	}
+	sort.SliceStable(results, func(i, j int) bool { return results[i].RegDate.After(results[j].RegDate) })
+	return results
+}
+
+func (a *buntInvitation) List(owner string, projectID primitive.ObjectID) []*models.Invitation {
+	return a.list(func(rec *models.Invitation) bool {
+		return rec.OwnerID == owner && rec.ProjectID == projectID
+	})
+}
+
+func (a *buntInvitation) ListByEmail(email string) []*models.Invitation {
+	return a.list(func(rec *models.Invitation) bool {
+		return rec.Email == email
+	})
+}
+
+func (a *buntInvitation) Get(id primitive.ObjectID) *models.Invitation {
+	for _, rec := range a.list(func(rec *models.Invitation) bool { return rec.ID == id }) {
+		return rec
+	}
+	return nil
+}
+
+func (a *buntInvitation) Find(hash string) *models.Invitation {
+	var data models.Invitation
+	if !a.findOne(a.key(hash), &data) {
+		return nil
+	}
+	return &data
+}
+
+func (a *buntInvitation) Create(data *models.Invitation) (*models.Invitation, error) {
+	if data.ID == primitive.NilObjectID {
+		data.ID = primitive.NewObjectID()
+	}
+	if err := a.insert(a.key(data.Hash), data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func (a *buntInvitation) Update(data *models.Invitation) (*models.Invitation, error) {
+	err := a.save(a.key(data.Hash), data)
+	return data, err
+}
+
+func (a *buntInvitation) DeleteAll(owner string, projectID primitive.ObjectID) error {
+	for _, rec := range a.List(owner, projectID) {
+		if err := a.remove(a.key(rec.Hash)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
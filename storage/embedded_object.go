@@ -0,0 +1,62 @@
+package storage
+
+import (
+	"fmt"
+
+	"bitbucket.org/toggly/toggly-server/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type buntObject struct {
+	buntCollection
+}
+
+func (a *buntObject) List(owner string, projectID primitive.ObjectID) []*models.Object {
+	results := make([]*models.Object, 0)
+	err := a.find(a.pattern(owner, projectID.Hex()), func(data []byte) error {
+		var rec models.Object
+		if err := bson.Unmarshal(data, &rec); err != nil {
+			return err
+		}
+		results = append(results, &rec)
+		return nil
+	})
+	if err != nil {
+		fmt.Println(err.Error())
+	}
+	return results
+}
+
+func (a *buntObject) Get(owner string, projectID primitive.ObjectID, instanceID string) *models.Object {
+	var data models.Object
+	a.findOne(a.key(owner, projectID.Hex(), instanceID), &data)
+	return &data
+}
+
+func (a *buntObject) Create(data *models.Object) (*models.Object, error) {
+	if data.ID == primitive.NilObjectID {
+		data.ID = primitive.NewObjectID()
+	}
+	if err := a.insert(a.key(data.OwnerID, data.ProjectID.Hex(), data.InstanceID), data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func (a *buntObject) Update(data *models.Object) (*models.Object, error) {
+	err := a.save(a.key(data.OwnerID, data.ProjectID.Hex(), data.InstanceID), data)
+	return data, err
+}
+
+func (a *buntObject) Delete(owner string, projectID primitive.ObjectID, instanceID string) error {
+	return a.remove(a.key(owner, projectID.Hex(), instanceID))
+}
+
+func (a *buntObject) DeleteAll(owner string, projectID primitive.ObjectID) error {
+	return a.remove(a.pattern(owner, projectID.Hex()))
+}
+
+func (a *buntObject) IsExist(owner string, projectID primitive.ObjectID, instanceID string) bool {
+	return a.count(a.key(owner, projectID.Hex(), instanceID)) != 0
+}
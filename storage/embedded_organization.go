@@ -0,0 +1,76 @@
+package storage
+
+import (
+	"fmt"
+	"sort"
+
+	"bitbucket.org/toggly/toggly-server/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// buntOrganization keeps organizations under codes, which makes codes unique
+type buntOrganization struct {
+	buntCollection
+}
+
+func (a *buntOrganization) List(user string) []*models.Organization {
+	results := make([]*models.Organization, 0)
+	err := a.find(a.pattern(), func(data []byte) error {
+		var rec models.Organization
+		if err := bson.Unmarshal(data, &rec); err != nil {
+			return err
+		}
+		if rec.Member(user) != nil {
+			results = append(results, &rec)
+		}
+		return nil
+	})
+	if err != nil {
+		fmt.Println(err.Error())
+	}
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Name < results[j].Name })
+	return results
+}
+
+func (a *buntOrganization) Get(code string) *models.Organization {
+	var data models.Organization
+	if !a.findOne(a.key(code), &data) {
+		return nil
+	}
+	return &data
+}
+
+func (a *buntOrganization) Find(id primitive.ObjectID) *models.Organization {
+	var result *models.Organization
+	a.find(a.pattern(), func(data []byte) error {
+		var rec models.Organization
+		if err := bson.Unmarshal(data, &rec); err != nil {
+			return err
+		}
+		if rec.ID == id {
+			result = &rec
+		}
+		return nil
+	})
+	return result
+}
+
+func (a *buntOrganization) Create(data *models.Organization) (*models.Organization, error) {
+	if data.ID == primitive.NilObjectID {
+		data.ID = primitive.NewObjectID()
+	}
+	if err := a.insert(a.key(data.Code), data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func (a *buntOrganization) Update(data *models.Organization) (*models.Organization, error) {
+	err := a.save(a.key(data.Code), data)
+	return data, err
+}
+
+func (a *buntOrganization) Delete(code string) error {
+	return a.remove(a.key(code))
+}
@@ -0,0 +1,90 @@
+package storage
+
+import (
+	"fmt"
+
+	"bitbucket.org/toggly/toggly-server/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// buntParameter keeps parameters under owner:environment:code keys
+type buntParameter struct {
+	buntCollection
+}
+
+func (a *buntParameter) list(pattern string, filter func(rec *models.Parameter) bool) []*models.Parameter {
+	results := make([]*models.Parameter, 0)
+	err := a.find(pattern, func(data []byte) error {
+		var rec models.Parameter
+		if err := bson.Unmarshal(data, &rec); err != nil {
+			return err
+		}
+		if filter(&rec) {
+			results = append(results, &rec)
+		}
+		return nil
+	})
+	if err != nil {
+		fmt.Println(err.Error())
+	}
+	return results
+}
+
+func (a *buntParameter) List(owner string, envID primitive.ObjectID) []*models.Parameter {
+	return a.list(a.pattern(owner, envID.Hex()), func(rec *models.Parameter) bool {
+		return true
+	})
+}
+
+func (a *buntParameter) ListByPackage(owner string, envID primitive.ObjectID, pkg string) []*models.Parameter {
+	return a.list(a.pattern(owner, envID.Hex()), func(rec *models.Parameter) bool {
+		return rec.Package == pkg
+	})
+}
+
+func (a *buntParameter) IsPackageUsed(owner string, projectID primitive.ObjectID, pkg string) bool {
+	return len(a.list(a.pattern(owner), func(rec *models.Parameter) bool {
+		return rec.ProjectID == projectID && rec.Package == pkg
+	})) != 0
+}
+
+func (a *buntParameter) Get(owner string, envID primitive.ObjectID, code string) *models.Parameter {
+	var data models.Parameter
+	a.findOne(a.key(owner, envID.Hex(), code), &data)
+	return &data
+}
+
+func (a *buntParameter) Create(data *models.Parameter) (*models.Parameter, error) {
+	if data.ID == primitive.NilObjectID {
+		data.ID = primitive.NewObjectID()
+	}
+	if err := a.insert(a.key(data.OwnerID, data.EnvironmentID.Hex(), data.Code), data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func (a *buntParameter) Update(data *models.Parameter) (*models.Parameter, error) {
+	err := a.save(a.key(data.OwnerID, data.EnvironmentID.Hex(), data.Code), data)
+	return data, err
+}
+
+func (a *buntParameter) Delete(owner string, envID primitive.ObjectID, code string) error {
+	return a.remove(a.key(owner, envID.Hex(), code))
+}
+
+func (a *buntParameter) DeleteAll(owner string, projectID primitive.ObjectID) error {
+	for _, rec := range a.list(a.pattern(owner), func(rec *models.Parameter) bool {
+		return rec.ProjectID == projectID
+	}) {
+		if err := a.remove(a.key(owner, rec.EnvironmentID.Hex(), rec.Code)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (a *buntParameter) IsExist(owner string, envID primitive.ObjectID, code string) bool {
+	return a.count(a.key(owner, envID.Hex(), code)) != 0
+}
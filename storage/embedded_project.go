@@ -0,0 +1,60 @@
+package storage
+
+import (
+	"fmt"
+	"sort"
+
+	"bitbucket.org/toggly/toggly-server/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type buntProject struct {
+	buntCollection
+}
+
+func (a *buntProject) List(owner string) []*models.Project {
+	results := make([]*models.Project, 0)
+	err := a.find(a.pattern(owner), func(data []byte) error {
+		var rec models.Project
+		if err := bson.Unmarshal(data, &rec); err != nil {
+			return err
+		}
+		results = append(results, &rec)
+		return nil
+	})
+	if err != nil {
+		fmt.Println(err.Error())
+	}
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Name < results[j].Name })
+	return results
+}
+
+func (a *buntProject) Get(owner string, code string) *models.Project {
+	var data models.Project
+	a.findOne(a.key(owner, code), &data)
+	return &data
+}
+
+func (a *buntProject) Create(data *models.Project) (*models.Project, error) {
+	if data.ID == primitive.NilObjectID {
+		data.ID = primitive.NewObjectID()
+	}
+	if err := a.insert(a.key(data.OwnerID, data.Code), data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func (a *buntProject) Update(data *models.Project) (*models.Project, error) {
+	err := a.save(a.key(data.OwnerID, data.Code), data)
+	return data, err
+}
+
+func (a *buntProject) Delete(owner string, code string) error {
+	return a.remove(a.key(owner, code))
+}
+
+func (a *buntProject) IsExist(owner string, code string) bool {
+	return a.count(a.key(owner, code)) != 0
+}
@@ -0,0 +1,90 @@
+package storage
+
+import (
+	"fmt"
+	"sort"
+
+	"bitbucket.org/toggly/toggly-server/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// buntSDKKey keeps keys under their hashes, so lookup by key doesn't need owner
+type buntSDKKey struct {
+	buntCollection
+}
+
+func (a *buntSDKKey) list(filter func(rec *models.SDKKey) bool) []*models.SDKKey {
+	results := make([]*models.SDKKey, 0)
+	err := a.find(a.pattern(), func(data []byte) error {
+		var rec models.SDKKey
+		if err := bson.Unmarshal(data, &rec); err != nil {
+			return err
+		}
+		if filter(&rec) {
+			results = append(results, &rec)
+		}
+		return nil
+	})
+	if err != nil {
+		fmt.Println(err.Error())
+	}
+	return results
+}
+
+func (a *buntSDKKey) List(owner string, envID primitive.ObjectID) []*models.SDKKey {
+	results := a.list(func(rec *models.SDKKey) bool {
+		return rec.OwnerID == owner && rec.EnvironmentID == envID
+	})
+	sort.SliceStable(results, func(i, j int) bool { return results[i].RegDate.Before(results[j].RegDate) })
+	return results
+}
+
+func (a *buntSDKKey) Find(hash string) *models.SDKKey {
+	var data models.SDKKey
+	if !a.findOne(a.key(hash), &data) {
+		return nil
+	}
+	return &data
+}
+
+func (a *buntSDKKey) Create(data *models.SDKKey) (*models.SDKKey, error) {
+	if data.ID == primitive.NilObjectID {
+		data.ID = primitive.NewObjectID()
+	}
+	if err := a.insert(a.key(data.Hash), data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func (a *buntSDKKey) Update(data *models.SDKKey) (*models.SDKKey, error) {
+	err := a.save(a.key(data.Hash), data)
+	return data, err
+}
+
+func (a *buntSDKKey) Delete(owner string, envID primitive.ObjectID, id primitive.ObjectID) error {
+	recs := a.list(func(rec *models.SDKKey) bool {
+		return rec.OwnerID == owner && rec.EnvironmentID == envID && rec.ID == id
+	})
+	if len(recs) == 0 {
+		return ErrNotFound
+	}
+	for _, rec := range recs {
+		if err := a.remove(a.key(rec.Hash)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (a *buntSDKKey) DeleteAll(owner string, projectID primitive.ObjectID) error {
+	for _, rec := range a.list(func(rec *models.SDKKey) bool {
+		return rec.OwnerID == owner && rec.ProjectID == projectID
+	}) {
+		if err := a.remove(a.key(rec.Hash)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
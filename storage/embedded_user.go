@@ -0,0 +1,74 @@
+package storage
+
+import (
+	"bitbucket.org/toggly/toggly-server/models"
+	"github.com/tidwall/buntdb"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// buntUser keeps users under ids, email keys point to user ids
+// for login and make emails unique
+type buntUser struct {
+	buntCollection
+}
+
+func (a *buntUser) idKey(id primitive.ObjectID) string {
+	return a.key("id", id.Hex())
+}
+
+func (a *buntUser) emailKey(email string) string {
+	return a.key("email", email)
+}
+
+func (a *buntUser) Get(id primitive.ObjectID) *models.User {
+	var data models.User
+	if !a.findOne(a.idKey(id), &data) {
+		return nil
+	}
+	return &data
+}
+
+func (a *buntUser) FindByEmail(email string) *models.User {
+	var value string
+	err := a.DB.View(func(tx *buntdb.Tx) error {
+		var err error
+		value, err = tx.Get(a.emailKey(email))
+		return err
+	})
+	if err != nil {
+		return nil
+	}
+	id, err := primitive.ObjectIDFromHex(value)
+	if err != nil {
+		return nil
+	}
+	return a.Get(id)
+}
+
+func (a *buntUser) Create(data *models.User) (*models.User, error) {
+	if data.ID == primitive.NilObjectID {
+		data.ID = primitive.NewObjectID()
+	}
+	body, err := bson.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	err = a.DB.Update(func(tx *buntdb.Tx) error {
+		if _, err := tx.Get(a.emailKey(data.Email)); err != buntdb.ErrNotFound {
+			if err != nil {
+				return err
+			}
+			return ErrDuplicateKey
+		}
+		if _, _, err := tx.Set(a.emailKey(data.Email), data.ID.Hex(), nil); err != nil {
+			return err
+		}
+		_, _, err := tx.Set(a.idKey(data.ID), string(body), nil)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
@@ -0,0 +1,96 @@
+package storage
+
+import (
+	"fmt"
+
+	"bitbucket.org/toggly/toggly-server/models"
+	"github.com/tidwall/buntdb"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// buntVersion keeps versions under owner:environment:code:version keys,
+// version number is zero padded to keep key order
+type buntVersion struct {
+	buntCollection
+}
+
+func (a *buntVersion) versionKey(owner string, envID primitive.ObjectID, code string, version int64) string {
+	return a.key(owner, envID.Hex(), code, fmt.Sprintf("%020d", version))
+}
+
+func (a *buntVersion) list(pattern string, filter func(rec *models.ParameterVersion) bool) []*models.ParameterVersion {
+	results := make([]*models.ParameterVersion, 0)
+	err := a.find(pattern, func(data []byte) error {
+		var rec models.ParameterVersion
+		if err := bson.Unmarshal(data, &rec); err != nil {
+			return err
+		}
+		if filter(&rec) {
+			results = append(results, &rec)
+		}
+		return nil
+	})
+	if err != nil {
+		fmt.Println(err.Error())
+	}
+	return results
+}
+
+func (a *buntVersion) List(owner string, envID primitive.ObjectID, code string) []*models.ParameterVersion {
+	results := a.list(a.pattern(owner, envID.Hex(), code), func(rec *models.ParameterVersion) bool {
+		return true
+	})
+	// newest first
+	for i, j := 0, len(results)-1; i < j; i, j = i+1, j-1 {
+		results[i], results[j] = results[j], results[i]
+	}
+	return results
+}
+
+func (a *buntVersion) Get(owner string, envID primitive.ObjectID, code string, version int64) *models.ParameterVersion {
+	var data models.ParameterVersion
+	a.findOne(a.versionKey(owner, envID, code, version), &data)
+	return &data
+}
+
+func (a *buntVersion) Create(data *models.ParameterVersion) (*models.ParameterVersion, error) {
+	if data.ID == primitive.NilObjectID {
+		data.ID = primitive.NewObjectID()
+	}
+	if err := a.insert(a.versionKey(data.OwnerID, data.EnvironmentID, data.Code, data.Version), data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func (a *buntVersion) DeleteAll(owner string, projectID primitive.ObjectID) error {
+	for _, rec := range a.list(a.pattern(owner), func(rec *models.ParameterVersion) bool {
+		return rec.ProjectID == projectID
+	}) {
+		if err := a.remove(a.versionKey(owner, rec.EnvironmentID, rec.Code, rec.Version)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (a *buntVersion) DeleteByEnvironment(owner string, envID primitive.ObjectID) error {
+	return a.remove(a.pattern(owner, envID.Hex()))
+}
+
+// Last returns number of the latest version, keys are ordered by version
+func (a *buntVersion) Last(owner string, envID primitive.ObjectID, code string) int64 {
+	var rec models.ParameterVersion
+	a.DB.View(func(tx *buntdb.Tx) error {
+		return tx.DescendKeys(a.pattern(owner, envID.Hex(), code), func(key, value string) bool {
+			bson.Unmarshal([]byte(value), &rec)
+			return false
+		})
+	})
+	return rec.Version
+}
+
+func (a *buntVersion) IsExist(owner string, envID primitive.ObjectID, code string, version int64) bool {
+	return a.count(a.versionKey(owner, envID, code, version)) != 0
+}
@@ -0,0 +1,109 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"bitbucket.org/toggly/toggly-server/models"
+	dbStore "github.com/nodely/go-mongo-store"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/x/bsonx"
+)
+
+type mgoEnvironment struct {
+	Storage *dbStore.DbStorage
+	CRUD    dbStore.CRUD
+}
+
+func (a *mgoEnvironment) List(owner string, projectID primitive.ObjectID) []*models.Environment {
+	results := make([]*models.Environment, 0)
+	cursor, err := a.CRUD.Find(bson.M{"owner_id": owner, "project_id": projectID}, options.Find().SetSort(bson.D{{"code", 1}}))
+	if err != nil {
+		fmt.Println(err.Error())
+		return results
+	}
+	for cursor.Next(context.TODO()) {
+		var rec models.Environment
+		cursor.Decode(&rec)
+		results = append(results, &rec)
+	}
+	return results
+}
+
+func (a *mgoEnvironment) Get(owner string, projectID primitive.ObjectID, code string) *models.Environment {
+	var data models.Environment
+	a.CRUD.FindOne(bson.M{"owner_id": owner, "project_id": projectID, "code": code}).Decode(&data)
+	return &data
+}
+
+func (a *mgoEnvironment) Create(data *models.Environment) (*models.Environment, error) {
+	// check index
+	if err := a.ensureIndexes(); err != nil {
+		return nil, err
+	}
+
+	ins, err := a.CRUD.Insert(data)
+	if err != nil {
+		return nil, err
+	}
+	rec, err := a.CRUD.GetItem(ins[0].(primitive.ObjectID), reflect.TypeOf(new(models.Environment)))
+	if err != nil {
+		return nil, err
+	}
+	return rec.(*models.Environment), nil
+}
+
+func (a *mgoEnvironment) Update(data *models.Environment) (*models.Environment, error) {
+	// check index
+	if err := a.ensureIndexes(); err != nil {
+		return nil, err
+	}
+
+	err := a.CRUD.SaveItem(data.ID, data)
+
+	return data, err
+}
+
+func (a *mgoEnvironment) Delete(owner string, projectID primitive.ObjectID, code string) error {
+	item := a.Get(owner, projectID, code)
+	if item.ID == primitive.NilObjectID {
+		return nil
+	}
+	return a.CRUD.DeleteItem(item.ID)
+}
+
+func (a *mgoEnvironment) DeleteAll(owner string, projectID primitive.ObjectID) error {
+	cursor, err := a.CRUD.Find(bson.M{"owner_id": owner, "project_id": projectID})
+	if err != nil {
+		return err
+	}
+	for cursor.Next(context.TODO()) {
+		var rec models.Environment
+		if err := cursor.Decode(&rec); err != nil {
+			return err
+		}
+		if err := a.CRUD.DeleteItem(rec.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (a *mgoEnvironment) IsExist(owner string, projectID primitive.ObjectID, code string) bool {
+	return a.CRUD.Count(bson.M{"owner_id": owner, "project_id": projectID, "code": code}) != 0
+}
+
+func (a *mgoEnvironment) ensureIndexes() error {
+	return a.CRUD.EnsureIndexesRaw(mongo.IndexModel{
+		Keys: bsonx.Doc{
+			{Key: "owner_id", Value: bsonx.Int32(1)},
+			{Key: "project_id", Value: bsonx.Int32(1)},
+			{Key: "code", Value: bsonx.Int32(1)},
+		},
+		Options: options.Index().SetUnique(true),
+	})
+}
@@ -0,0 +1,36 @@
+package storage
+
+import (
+	"fmt"
+
+	"bitbucket.org/toggly/toggly-server/models"
+)
+
+// Factory creates storage for configuration
+type Factory func(cfg *models.Storage) (Storage, error)
+
+var drivers = make(map[string]Factory)
+
+// Register makes storage driver available by name
+func Register(driver string, factory Factory) {
+	if factory == nil {
+		panic("storage: Register factory is nil")
+	}
+	if _, dup := drivers[driver]; dup {
+		panic("storage: Register called twice for driver " + driver)
+	}
+	drivers[driver] = factory
+}
+
+// New creates storage for configured driver, mongodb is used by default
+func New(cfg *models.Storage) (Storage, error) {
+	driver := cfg.Driver
+	if driver == "" {
+		driver = DriverMongoDB
+	}
+	factory, ok := drivers[driver]
+	if !ok {
+		return nil, fmt.Errorf("storage: unknown driver %q", driver)
+	}
+	return factory(cfg)
+}
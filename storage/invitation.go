@@ -0,0 +1,95 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"bitbucket.org/toggly/toggly-server/models"
+	dbStore "github.com/nodely/go-mongo-store"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/x/bsonx"
+)
+
+type mgoInvitation struct {
+	Storage *dbStore.DbStorage
+	CRUD    dbStore.CRUD
+}
+
+func (a *mgoInvitation) find(filter bson.M) []*models.Invitation {
+	results := make([]*models.Invitation, 0)
+	cursor, err := a.CRUD.Find(filter, options.Find().SetSort(bson.D{{"reg_date", -1}}))
+	if err != nil {
+		fmt.Println(err.Error())
+		return results
+	}
+	for cursor.Next(context.TODO()) {
+		var rec models.Invitation
+		cursor.Decode(&rec)
+		results = append(results, &rec)
+	}
+	return results
+}
+
+func (a *mgoInvitation) List(owner string, projectID primitive.ObjectID) []*models.Invitation {
+	return a.find(bson.M{"owner_id": owner, "project_id": projectID})
+}
+
+func (a *mgoInvitation) ListByEmail(email string) []*models.Invitation {
+	return a.find(bson.M{"email": email})
+}
+
+func (a *mgoInvitation) Get(id primitive.ObjectID) *models.Invitation {
+	var data models.Invitation
+	if err := a.CRUD.FindOne(bson.M{"_id": id}).Decode(&data); err != nil {
+		return nil
+	}
+	return &data
+}
+
+func (a *mgoInvitation) Find(hash string) *models.Invitation {
+	var data models.Invitation
+	if err := a.CRUD.FindOne(bson.M{"hash": hash}).Decode(&data); err != nil {
+		return nil
+	}
+	return &data
+}
+
+func (a *mgoInvitation) Create(data *models.Invitation) (*models.Invitation, error) {
+	// check index
+	if err := a.ensureIndexes(); err != nil {
+		return nil, err
+	}
+
+	ins, err := a.CRUD.Insert(data)
+	if err != nil {
+		return nil, err
+	}
+	data.ID = ins[0].(primitive.ObjectID)
+	return data, nil
+}
+
+func (a *mgoInvitation) Update(data *models.Invitation) (*models.Invitation, error) {
+	err := a.CRUD.SaveItem(data.ID, data)
+	return data, err
+}
+
+func (a *mgoInvitation) DeleteAll(owner string, projectID primitive.ObjectID) error {
+	for _, rec := range a.List(owner, projectID) {
+		if err := a.CRUD.DeleteItem(rec.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (a *mgoInvitation) ensureIndexes() error {
+	return a.CRUD.EnsureIndexesRaw(mongo.IndexModel{
+		Keys: bsonx.Doc{
+			{Key: "hash", Value: bsonx.Int32(1)},
+		},
+		Options: options.Index().SetUnique(true),
+	})
+}
@@ -1,14 +1,37 @@
 package storage
 
 import (
+	"bitbucket.org/toggly/toggly-server/models"
 	dbStore "github.com/nodely/go-mongo-store"
 )
 
-// Storage struct
+// DriverMongoDB storage driver name
+const DriverMongoDB = "mongodb"
+
+func init() {
+	Register(DriverMongoDB, NewMongoStorage)
+}
+
+// MongoStorage struct
 type MongoStorage struct {
 	Dbs *dbStore.DbStorage
 }
 
+// NewMongoStorage connects to mongodb storage
+func NewMongoStorage(cfg *models.Storage) (Storage, error) {
+	dbs, err := dbStore.NewMongoStorage(cfg.Connection)
+	if err != nil {
+		return nil, err
+	}
+	// set db
+	dbs.WithName(cfg.Name)
+	db := &MongoStorage{Dbs: dbs}
+	if err := (&mgoProject{Storage: dbs, CRUD: db.GetProjectsCollection()}).migrate(); err != nil {
+		return nil, err
+	}
+	return db, nil
+}
+
 // GetProjectsCollection func
 func (db *MongoStorage) GetProjectsCollection() dbStore.CRUD {
 	return db.Dbs.GetDbCollection("projects")
@@ -34,7 +57,97 @@ func (db *MongoStorage) GetParamsCollection() dbStore.CRUD {
 	return db.Dbs.GetDbCollection("params")
 }
 
+// GetAuditCollection func
+func (db *MongoStorage) GetAuditCollection() dbStore.CRUD {
+	return db.Dbs.GetDbCollection("audit")
+}
+
+// GetVersionsCollection func
+func (db *MongoStorage) GetVersionsCollection() dbStore.CRUD {
+	return db.Dbs.GetDbCollection("versions")
+}
+
+// GetChangesCollection func
+func (db *MongoStorage) GetChangesCollection() dbStore.CRUD {
+	return db.Dbs.GetDbCollection("changes")
+}
+
+// GetSDKKeysCollection func
+func (db *MongoStorage) GetSDKKeysCollection() dbStore.CRUD {
+	return db.Dbs.GetDbCollection("sdk_keys")
+}
+
+// GetUsersCollection func
+func (db *MongoStorage) GetUsersCollection() dbStore.CRUD {
+	return db.Dbs.GetDbCollection("users")
+}
+
+// GetOrganizationsCollection func
+func (db *MongoStorage) GetOrganizationsCollection() dbStore.CRUD {
+	return db.Dbs.GetDbCollection("organizations")
+}
+
+// GetInvitationsCollection func
+func (db *MongoStorage) GetInvitationsCollection() dbStore.CRUD {
+	return db.Dbs.GetDbCollection("invitations")
+}
+
 // ProjectCRUD func
 func (db *MongoStorage) ProjectCRUD() Project {
 	return &mgoProject{Storage: db.Dbs, CRUD: db.GetProjectsCollection()}
 }
+
+// EnvironmentCRUD func
+func (db *MongoStorage) EnvironmentCRUD() Environment {
+	return &mgoEnvironment{Storage: db.Dbs, CRUD: db.GetEnvsCollection()}
+}
+
+// ParameterCRUD func
+func (db *MongoStorage) ParameterCRUD() Parameter {
+	return &mgoParameter{Storage: db.Dbs, CRUD: db.GetParamsCollection()}
+}
+
+// PackageCRUD func
+func (db *MongoStorage) PackageCRUD() Package {
+	return &mgoPackage{Storage: db.Dbs, CRUD: db.GetPackagesCollection()}
+}
+
+// ObjectCRUD func
+func (db *MongoStorage) ObjectCRUD() Object {
+	return &mgoObject{Storage: db.Dbs, CRUD: db.GetObjectsCollection()}
+}
+
+// AuditCRUD func
+func (db *MongoStorage) AuditCRUD() Audit {
+	return &mgoAudit{Storage: db.Dbs, CRUD: db.GetAuditCollection()}
+}
+
+// VersionCRUD func
+func (db *MongoStorage) VersionCRUD() Version {
+	return &mgoVersion{Storage: db.Dbs, CRUD: db.GetVersionsCollection()}
+}
+
+// ChangeCRUD func
+func (db *MongoStorage) ChangeCRUD() Change {
+	return &mgoChange{Storage: db.Dbs, CRUD: db.GetChangesCollection()}
+}
+
+// SDKKeyCRUD func
+func (db *MongoStorage) SDKKeyCRUD() SDKKey {
+	return &mgoSDKKey{Storage: db.Dbs, CRUD: db.GetSDKKeysCollection()}
+}
+
+// UserCRUD func
+func (db *MongoStorage) UserCRUD() User {
+	return &mgoUser{Storage: db.Dbs, CRUD: db.GetUsersCollection()}
+}
+
+// OrganizationCRUD func
+func (db *MongoStorage) OrganizationCRUD() Organization {
+	return &mgoOrganization{Storage: db.Dbs, CRUD: db.GetOrganizationsCollection()}
+}
+
+// InvitationCRUD func
+func (db *MongoStorage) InvitationCRUD() Invitation {
+	return &mgoInvitation{Storage: db.Dbs, CRUD: db.GetInvitationsCollection()}
+}
@@ -0,0 +1,109 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"bitbucket.org/toggly/toggly-server/models"
+	dbStore "github.com/nodely/go-mongo-store"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/x/bsonx"
+)
+
+type mgoObject struct {
+	Storage *dbStore.DbStorage
+	CRUD    dbStore.CRUD
+}
+
+func (a *mgoObject) List(owner string, projectID primitive.ObjectID) []*models.Object {
+	results := make([]*models.Object, 0)
+	cursor, err := a.CRUD.Find(bson.M{"owner_id": owner, "project_id": projectID}, options.Find().SetSort(bson.D{{"instance_id", 1}}))
+	if err != nil {
+		fmt.Println(err.Error())
+		return results
+	}
+	for cursor.Next(context.TODO()) {
+		var rec models.Object
+		cursor.Decode(&rec)
+		results = append(results, &rec)
+	}
+	return results
+}
+
+func (a *mgoObject) Get(owner string, projectID primitive.ObjectID, instanceID string) *models.Object {
+	var data models.Object
+	a.CRUD.FindOne(bson.M{"owner_id": owner, "project_id": projectID, "instance_id": instanceID}).Decode(&data)
+	return &data
+}
+
+func (a *mgoObject) Create(data *models.Object) (*models.Object, error) {
+	// check index
+	if err := a.ensureIndexes(); err != nil {
+		return nil, err
+	}
+
+	ins, err := a.CRUD.Insert(data)
+	if err != nil {
+		return nil, err
+	}
+	rec, err := a.CRUD.GetItem(ins[0].(primitive.ObjectID), reflect.TypeOf(new(models.Object)))
+	if err != nil {
+		return nil, err
+	}
+	return rec.(*models.Object), nil
+}
+
+func (a *mgoObject) Update(data *models.Object) (*models.Object, error) {
+	// check index
+	if err := a.ensureIndexes(); err != nil {
+		return nil, err
+	}
+
+	err := a.CRUD.SaveItem(data.ID, data)
+
+	return data, err
+}
+
+func (a *mgoObject) Delete(owner string, projectID primitive.ObjectID, instanceID string) error {
+	item := a.Get(owner, projectID, instanceID)
+	if item.ID == primitive.NilObjectID {
+		return nil
+	}
+	return a.CRUD.DeleteItem(item.ID)
+}
+
+func (a *mgoObject) DeleteAll(owner string, projectID primitive.ObjectID) error {
+	cursor, err := a.CRUD.Find(bson.M{"owner_id": owner, "project_id": projectID})
+	if err != nil {
+		return err
+	}
+	for cursor.Next(context.TODO()) {
+		var rec models.Object
+		if err := cursor.Decode(&rec); err != nil {
+			return err
+		}
+		if err := a.CRUD.DeleteItem(rec.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (a *mgoObject) IsExist(owner string, projectID primitive.ObjectID, instanceID string) bool {
+	return a.CRUD.Count(bson.M{"owner_id": owner, "project_id": projectID, "instance_id": instanceID}) != 0
+}
+
+func (a *mgoObject) ensureIndexes() error {
+	return a.CRUD.EnsureIndexesRaw(mongo.IndexModel{
+		Keys: bsonx.Doc{
+			{Key: "owner_id", Value: bsonx.Int32(1)},
+			{Key: "project_id", Value: bsonx.Int32(1)},
+			{Key: "instance_id", Value: bsonx.Int32(1)},
+		},
+		Options: options.Index().SetUnique(true),
+	})
+}
@@ -0,0 +1,90 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"bitbucket.org/toggly/toggly-server/models"
+	dbStore "github.com/nodely/go-mongo-store"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/x/bsonx"
+)
+
+type mgoOrganization struct {
+	Storage *dbStore.DbStorage
+	CRUD    dbStore.CRUD
+}
+
+func (a *mgoOrganization) List(user string) []*models.Organization {
+	results := make([]*models.Organization, 0)
+	cursor, err := a.CRUD.Find(bson.M{"members.user_id": user}, options.Find().SetSort(bson.D{{"name", 1}}))
+	if err != nil {
+		fmt.Println(err.Error())
+		return results
+	}
+	for cursor.Next(context.TODO()) {
+		var rec models.Organization
+		cursor.Decode(&rec)
+		results = append(results, &rec)
+	}
+	return results
+}
+
+func (a *mgoOrganization) Get(code string) *models.Organization {
+	var data models.Organization
+	if err := a.CRUD.FindOne(bson.M{"code": code}).Decode(&data); err != nil {
+		return nil
+	}
+	return &data
+}
+
+func (a *mgoOrganization) Find(id primitive.ObjectID) *models.Organization {
+	var data models.Organization
+	if err := a.CRUD.FindOne(bson.M{"_id": id}).Decode(&data); err != nil {
+		return nil
+	}
+	return &data
+}
+
+func (a *mgoOrganization) Create(data *models.Organization) (*models.Organization, error) {
+	// check index
+	if err := a.ensureIndexes(); err != nil {
+		return nil, err
+	}
+
+	ins, err := a.CRUD.Insert(data)
+	if err != nil {
+		return nil, err
+	}
+	rec, err := a.CRUD.GetItem(ins[0].(primitive.ObjectID), reflect.TypeOf(new(models.Organization)))
+	if err != nil {
+		return nil, err
+	}
+	return rec.(*models.Organization), nil
+}
+
+func (a *mgoOrganization) Update(data *models.Organization) (*models.Organization, error) {
+	err := a.CRUD.SaveItem(data.ID, data)
+	return data, err
+}
+
+func (a *mgoOrganization) Delete(code string) error {
+	item := a.Get(code)
+	if item == nil {
+		return nil
+	}
+	return a.CRUD.DeleteItem(item.ID)
+}
+
+func (a *mgoOrganization) ensureIndexes() error {
+	return a.CRUD.EnsureIndexesRaw(mongo.IndexModel{
+		Keys: bsonx.Doc{
+			{Key: "code", Value: bsonx.Int32(1)},
+		},
+		Options: options.Index().SetUnique(true),
+	})
+}
@@ -0,0 +1,109 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"bitbucket.org/toggly/toggly-server/models"
+	dbStore "github.com/nodely/go-mongo-store"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/x/bsonx"
+)
+
+type mgoPackage struct {
+	Storage *dbStore.DbStorage
+	CRUD    dbStore.CRUD
+}
+
+func (a *mgoPackage) List(owner string, projectID primitive.ObjectID) []*models.Package {
+	results := make([]*models.Package, 0)
+	cursor, err := a.CRUD.Find(bson.M{"owner_id": owner, "project_id": projectID}, options.Find().SetSort(bson.D{{"code", 1}}))
+	if err != nil {
+		fmt.Println(err.Error())
+		return results
+	}
+	for cursor.Next(context.TODO()) {
+		var rec models.Package
+		cursor.Decode(&rec)
+		results = append(results, &rec)
+	}
+	return results
+}
+
+func (a *mgoPackage) Get(owner string, projectID primitive.ObjectID, code string) *models.Package {
+	var data models.Package
+	a.CRUD.FindOne(bson.M{"owner_id": owner, "project_id": projectID, "code": code}).Decode(&data)
+	return &data
+}
+
+func (a *mgoPackage) Create(data *models.Package) (*models.Package, error) {
+	// check index
+	if err := a.ensureIndexes(); err != nil {
+		return nil, err
+	}
+
+	ins, err := a.CRUD.Insert(data)
+	if err != nil {
+		return nil, err
+	}
+	rec, err := a.CRUD.GetItem(ins[0].(primitive.ObjectID), reflect.TypeOf(new(models.Package)))
+	if err != nil {
+		return nil, err
+	}
+	return rec.(*models.Package), nil
+}
+
+func (a *mgoPackage) Update(data *models.Package) (*models.Package, error) {
+	// check index
+	if err := a.ensureIndexes(); err != nil {
+		return nil, err
+	}
+
+	err := a.CRUD.SaveItem(data.ID, data)
+
+	return data, err
+}
+
+func (a *mgoPackage) Delete(owner string, projectID primitive.ObjectID, code string) error {
+	item := a.Get(owner, projectID, code)
+	if item.ID == primitive.NilObjectID {
+		return nil
+	}
+	return a.CRUD.DeleteItem(item.ID)
+}
+
+func (a *mgoPackage) DeleteAll(owner string, projectID primitive.ObjectID) error {
+	cursor, err := a.CRUD.Find(bson.M{"owner_id": owner, "project_id": projectID})
+	if err != nil {
+		return err
+	}
+	for cursor.Next(context.TODO()) {
+		var rec models.Package
+		if err := cursor.Decode(&rec); err != nil {
+			return err
+		}
+		if err := a.CRUD.DeleteItem(rec.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (a *mgoPackage) IsExist(owner string, projectID primitive.ObjectID, code string) bool {
+	return a.CRUD.Count(bson.M{"owner_id": owner, "project_id": projectID, "code": code}) != 0
+}
+
+func (a *mgoPackage) ensureIndexes() error {
+	return a.CRUD.EnsureIndexesRaw(mongo.IndexModel{
+		Keys: bsonx.Doc{
+			{Key: "owner_id", Value: bsonx.Int32(1)},
+			{Key: "project_id", Value: bsonx.Int32(1)},
+			{Key: "code", Value: bsonx.Int32(1)},
+		},
+		Options: options.Index().SetUnique(true),
+	})
+}
@@ -0,0 +1,129 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"bitbucket.org/toggly/toggly-server/models"
+	dbStore "github.com/nodely/go-mongo-store"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/x/bsonx"
+)
+
+type mgoParameter struct {
+	Storage *dbStore.DbStorage
+	CRUD    dbStore.CRUD
+}
+
+func (a *mgoParameter) List(owner string, envID primitive.ObjectID) []*models.Parameter {
+	results := make([]*models.Parameter, 0)
+	cursor, err := a.CRUD.Find(bson.M{"owner_id": owner, "env_id": envID}, options.Find().SetSort(bson.D{{"code", 1}}))
+	if err != nil {
+		fmt.Println(err.Error())
+		return results
+	}
+	for cursor.Next(context.TODO()) {
+		var rec models.Parameter
+		cursor.Decode(&rec)
+		results = append(results, &rec)
+	}
+	return results
+}
+
+func (a *mgoParameter) ListByPackage(owner string, envID primitive.ObjectID, pkg string) []*models.Parameter {
+	results := make([]*models.Parameter, 0)
+	cursor, err := a.CRUD.Find(bson.M{"owner_id": owner, "env_id": envID, "package": pkg}, options.Find().SetSort(bson.D{{"code", 1}}))
+	if err != nil {
+		fmt.Println(err.Error())
+		return results
+	}
+	for cursor.Next(context.TODO()) {
+		var rec models.Parameter
+		cursor.Decode(&rec)
+		results = append(results, &rec)
+	}
+	return results
+}
+
+func (a *mgoParameter) IsPackageUsed(owner string, projectID primitive.ObjectID, pkg string) bool {
+	return a.CRUD.Count(bson.M{"owner_id": owner, "project_id": projectID, "package": pkg}) != 0
+}
+
+func (a *mgoParameter) Get(owner string, envID primitive.ObjectID, code string) *models.Parameter {
+	var data models.Parameter
+	a.CRUD.FindOne(bson.M{"owner_id": owner, "env_id": envID, "code": code}).Decode(&data)
+	return &data
+}
+
+func (a *mgoParameter) Create(data *models.Parameter) (*models.Parameter, error) {
+	// check index
+	if err := a.ensureIndexes(); err != nil {
+		return nil, err
+	}
+
+	ins, err := a.CRUD.Insert(data)
+	if err != nil {
+		return nil, err
+	}
+	rec, err := a.CRUD.GetItem(ins[0].(primitive.ObjectID), reflect.TypeOf(new(models.Parameter)))
+	if err != nil {
+		return nil, err
+	}
+	return rec.(*models.Parameter), nil
+}
+
+func (a *mgoParameter) Update(data *models.Parameter) (*models.Parameter, error) {
+	// check index
+	if err := a.ensureIndexes(); err != nil {
+		return nil, err
+	}
+
+	err := a.CRUD.SaveItem(data.ID, data)
+
+	return data, err
+}
+
+func (a *mgoParameter) Delete(owner string, envID primitive.ObjectID, code string) error {
+	item := a.Get(owner, envID, code)
+	if item.ID == primitive.NilObjectID {
+		return nil
+	}
+	return a.CRUD.DeleteItem(item.ID)
+}
+
+func (a *mgoParameter) DeleteAll(owner string, projectID primitive.ObjectID) error {
+	cursor, err := a.CRUD.Find(bson.M{"owner_id": owner, "project_id": projectID})
+	if err != nil {
+		return err
+	}
+	for cursor.Next(context.TODO()) {
+		var rec models.Parameter
+		if err := cursor.Decode(&rec); err != nil {
+			return err
+		}
+		if err := a.CRUD.DeleteItem(rec.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (a *mgoParameter) IsExist(owner string, envID primitive.ObjectID, code string) bool {
+	return a.CRUD.Count(bson.M{"owner_id": owner, "env_id": envID, "code": code}) != 0
+}
+
+func (a *mgoParameter) ensureIndexes() error {
+	return a.CRUD.EnsureIndexesRaw(mongo.IndexModel{
+		Keys: bsonx.Doc{
+			{Key: "owner_id", Value: bsonx.Int32(1)},
+			{Key: "project_id", Value: bsonx.Int32(1)},
+			{Key: "env_id", Value: bsonx.Int32(1)},
+			{Key: "code", Value: bsonx.Int32(1)},
+		},
+		Options: options.Index().SetUnique(true),
+	})
+}
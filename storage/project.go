@@ -19,9 +19,9 @@ type mgoProject struct {
 	CRUD    dbStore.CRUD
 }
 
-func (a *mgoProject) List() []*models.Project {
+func (a *mgoProject) List(owner string) []*models.Project {
 	results := make([]*models.Project, 0)
-	cursor, err := a.CRUD.Find(bson.D{{}}, options.Find().SetSort(bson.D{{"name", 1}}))
+	cursor, err := a.CRUD.Find(bson.M{"owner_id": owner}, options.Find().SetSort(bson.D{{"name", 1}}))
 	if err != nil {
 		fmt.Println(err.Error())
 		return results
@@ -34,9 +34,9 @@ func (a *mgoProject) List() []*models.Project {
 	return results
 }
 
-func (a *mgoProject) Get(code string) *models.Project {
+func (a *mgoProject) Get(owner string, code string) *models.Project {
 	var data models.Project
-	a.CRUD.FindOne(bson.M{"code": code}).Decode(&data)
+	a.CRUD.FindOne(bson.M{"owner_id": owner, "code": code}).Decode(&data)
 	return &data
 }
 
@@ -68,12 +68,35 @@ func (a *mgoProject) Update(data *models.Project) (*models.Project, error) {
 	return data, err
 }
 
-func (a *mgoProject) Delete(code string) {
+func (a *mgoProject) Delete(owner string, code string) error {
+	item := a.Get(owner, code)
+	if item.ID == primitive.NilObjectID {
+		return nil
+	}
+	return a.CRUD.DeleteItem(item.ID)
+}
 
+func (a *mgoProject) IsExist(owner string, code string) bool {
+	return a.CRUD.Count(bson.M{"owner_id": owner, "code": code}) != 0
 }
 
-func (a *mgoProject) IsExist(code string) bool {
-	return a.CRUD.Count(bson.M{"code": code}) != 0
+// migrate rewrites numeric statuses of projects created before archival existed,
+// they are decoded as active
+func (a *mgoProject) migrate() error {
+	cursor, err := a.CRUD.Find(bson.M{"status": bson.M{"$type": "number"}})
+	if err != nil {
+		return err
+	}
+	for cursor.Next(context.TODO()) {
+		var rec models.Project
+		if err := cursor.Decode(&rec); err != nil {
+			return err
+		}
+		if err := a.CRUD.SaveItem(rec.ID, &rec); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (a *mgoProject) ensureIndexes() error {
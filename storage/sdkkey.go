@@ -0,0 +1,94 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"bitbucket.org/toggly/toggly-server/models"
+	dbStore "github.com/nodely/go-mongo-store"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/x/bsonx"
+)
+
+type mgoSDKKey struct {
+	Storage *dbStore.DbStorage
+	CRUD    dbStore.CRUD
+}
+
+func (a *mgoSDKKey) List(owner string, envID primitive.ObjectID) []*models.SDKKey {
+	results := make([]*models.SDKKey, 0)
+	cursor, err := a.CRUD.Find(bson.M{"owner_id": owner, "env_id": envID}, options.Find().SetSort(bson.D{{"reg_date", 1}}))
+	if err != nil {
+		fmt.Println(err.Error())
+		return results
+	}
+	for cursor.Next(context.TODO()) {
+		var rec models.SDKKey
+		cursor.Decode(&rec)
+		results = append(results, &rec)
+	}
+	return results
+}
+
+func (a *mgoSDKKey) Find(hash string) *models.SDKKey {
+	var data models.SDKKey
+	if err := a.CRUD.FindOne(bson.M{"hash": hash}).Decode(&data); err != nil {
+		return nil
+	}
+	return &data
+}
+
+func (a *mgoSDKKey) Create(data *models.SDKKey) (*models.SDKKey, error) {
+	// check index
+	if err := a.ensureIndexes(); err != nil {
+		return nil, err
+	}
+
+	ins, err := a.CRUD.Insert(data)
+	if err != nil {
+		return nil, err
+	}
+	data.ID = ins[0].(primitive.ObjectID)
+	return data, nil
+}
+
+func (a *mgoSDKKey) Update(data *models.SDKKey) (*models.SDKKey, error) {
+	err := a.CRUD.SaveItem(data.ID, data)
+	return data, err
+}
+
+func (a *mgoSDKKey) Delete(owner string, envID primitive.ObjectID, id primitive.ObjectID) error {
+	if a.CRUD.Count(bson.M{"owner_id": owner, "env_id": envID, "_id": id}) == 0 {
+		return ErrNotFound
+	}
+	return a.CRUD.DeleteItem(id)
+}
+
+func (a *mgoSDKKey) DeleteAll(owner string, projectID primitive.ObjectID) error {
+	cursor, err := a.CRUD.Find(bson.M{"owner_id": owner, "project_id": projectID})
+	if err != nil {
+		return err
+	}
+	for cursor.Next(context.TODO()) {
+		var rec models.SDKKey
+		if err := cursor.Decode(&rec); err != nil {
+			return err
+		}
+		if err := a.CRUD.DeleteItem(rec.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (a *mgoSDKKey) ensureIndexes() error {
+	return a.CRUD.EnsureIndexesRaw(mongo.IndexModel{
+		Keys: bsonx.Doc{
+			{Key: "hash", Value: bsonx.Int32(1)},
+		},
+		Options: options.Index().SetUnique(true),
+	})
+}
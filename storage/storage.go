@@ -1,17 +1,159 @@
 package storage
 
-import "bitbucket.org/toggly/toggly-server/models"
+import (
+	"errors"
+	"strings"
+
+	"bitbucket.org/toggly/toggly-server/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ErrDuplicateKey is returned when record violates unique constraint
+var ErrDuplicateKey = errors.New("duplicate key")
+
+// ErrNotFound is returned when deleted record doesn't exist
+var ErrNotFound = errors.New("not found")
+
+// IsDuplicateKey checks that error is caused by unique constraint violation
+func IsDuplicateKey(err error) bool {
+	return err == ErrDuplicateKey || strings.Contains(err.Error(), "E11000")
+}
+
+// Storage interface
+type Storage interface {
+	ProjectCRUD() Project
+	EnvironmentCRUD() Environment
+	PackageCRUD() Package
+	ObjectCRUD() Object
+	ParameterCRUD() Parameter
+	AuditCRUD() Audit
+	VersionCRUD() Version
+	ChangeCRUD() Change
+	SDKKeyCRUD() SDKKey
+	UserCRUD() User
+	OrganizationCRUD() Organization
+	InvitationCRUD() Invitation
+}
 
 // Project interface
 type Project interface {
-	List() []*models.Project
-	Get(code string) *models.Project
+	List(owner string) []*models.Project
+	Get(owner string, code string) *models.Project
 	Create(data *models.Project) (*models.Project, error)
 	Update(data *models.Project) (*models.Project, error)
-	Delete(code string)
-	IsExist(code string) bool
+	Delete(owner string, code string) error
+	IsExist(owner string, code string) bool
 }
 
 // Environment interface
 type Environment interface {
+	List(owner string, projectID primitive.ObjectID) []*models.Environment
+	Get(owner string, projectID primitive.ObjectID, code string) *models.Environment
+	Create(data *models.Environment) (*models.Environment, error)
+	Update(data *models.Environment) (*models.Environment, error)
+	Delete(owner string, projectID primitive.ObjectID, code string) error
+	DeleteAll(owner string, projectID primitive.ObjectID) error
+	IsExist(owner string, projectID primitive.ObjectID, code string) bool
+}
+
+// Parameter interface
+type Parameter interface {
+	List(owner string, envID primitive.ObjectID) []*models.Parameter
+	ListByPackage(owner string, envID primitive.ObjectID, pkg string) []*models.Parameter
+	IsPackageUsed(owner string, projectID primitive.ObjectID, pkg string) bool
+	Get(owner string, envID primitive.ObjectID, code string) *models.Parameter
+	Create(data *models.Parameter) (*models.Parameter, error)
+	Update(data *models.Parameter) (*models.Parameter, error)
+	Delete(owner string, envID primitive.ObjectID, code string) error
+	DeleteAll(owner string, projectID primitive.ObjectID) error
+	IsExist(owner string, envID primitive.ObjectID, code string) bool
+}
+
+// Package interface
+type Package interface {
+	List(owner string, projectID primitive.ObjectID) []*models.Package
+	Get(owner string, projectID primitive.ObjectID, code string) *models.Package
+	Create(data *models.Package) (*models.Package, error)
+	Update(data *models.Package) (*models.Package, error)
+	Delete(owner string, projectID primitive.ObjectID, code string) error
+	DeleteAll(owner string, projectID primitive.ObjectID) error
+	IsExist(owner string, projectID primitive.ObjectID, code string) bool
+}
+
+// Object interface
+type Object interface {
+	List(owner string, projectID primitive.ObjectID) []*models.Object
+	Get(owner string, projectID primitive.ObjectID, instanceID string) *models.Object
+	Create(data *models.Object) (*models.Object, error)
+	Update(data *models.Object) (*models.Object, error)
+	Delete(owner string, projectID primitive.ObjectID, instanceID string) error
+	DeleteAll(owner string, projectID primitive.ObjectID) error
+	IsExist(owner string, projectID primitive.ObjectID, instanceID string) bool
+}
+
+// Version interface
+type Version interface {
+	List(owner string, envID primitive.ObjectID, code string) []*models.ParameterVersion
+	Get(owner string, envID primitive.ObjectID, code string, version int64) *models.ParameterVersion
+	Create(data *models.ParameterVersion) (*models.ParameterVersion, error)
+	DeleteAll(owner string, projectID primitive.ObjectID) error
+	DeleteByEnvironment(owner string, envID primitive.ObjectID) error
+	Last(owner string, envID primitive.ObjectID, code string) int64
+	IsExist(owner string, envID primitive.ObjectID, code string, version int64) bool
+}
+
+// Change interface
+type Change interface {
+	List(owner string, projectID primitive.ObjectID) []*models.ChangeRequest
+	Get(owner string, projectID primitive.ObjectID, id primitive.ObjectID) *models.ChangeRequest
+	Create(data *models.ChangeRequest) (*models.ChangeRequest, error)
+	Update(data *models.ChangeRequest) (*models.ChangeRequest, error)
+	DeleteAll(owner string, projectID primitive.ObjectID) error
+	DeleteByEnvironment(owner string, projectID primitive.ObjectID, envID primitive.ObjectID) error
+	IsExist(owner string, projectID primitive.ObjectID, id primitive.ObjectID) bool
+}
+
+// SDKKey interface, Find looks key up by hash across all owners
+type SDKKey interface {
+	List(owner string, envID primitive.ObjectID) []*models.SDKKey
+	Find(hash string) *models.SDKKey
+	Create(data *models.SDKKey) (*models.SDKKey, error)
+	Update(data *models.SDKKey) (*models.SDKKey, error)
+	Delete(owner string, envID primitive.ObjectID, id primitive.ObjectID) error
+	DeleteAll(owner string, projectID primitive.ObjectID) error
+}
+
+// User interface, users are not owned by anyone
+type User interface {
+	Get(id primitive.ObjectID) *models.User
+	FindByEmail(email string) *models.User
+	Create(data *models.User) (*models.User, error)
+}
+
+// Organization interface, Find looks organization up by id which owns its projects
+type Organization interface {
+	List(user string) []*models.Organization
+	Get(code string) *models.Organization
+	Find(id primitive.ObjectID) *models.Organization
+	Create(data *models.Organization) (*models.Organization, error)
+	Update(data *models.Organization) (*models.Organization, error)
+	Delete(code string) error
+}
+
+// Invitation interface, organization invitations have nil project id,
+// Find looks invitation up by token hash
+type Invitation interface {
+	List(owner string, projectID primitive.ObjectID) []*models.Invitation
+	ListByEmail(email string) []*models.Invitation
+	Get(id primitive.ObjectID) *models.Invitation
+	Find(hash string) *models.Invitation
+	Create(data *models.Invitation) (*models.Invitation, error)
+	Update(data *models.Invitation) (*models.Invitation, error)
+	DeleteAll(owner string, projectID primitive.ObjectID) error
+}
+
+// Audit interface
+type Audit interface {
+	List(owner string, filter *models.AuditFilter) []*models.AuditRecord
+	Create(data *models.AuditRecord) (*models.AuditRecord, error)
 }
@@ -0,0 +1,60 @@
+package storage
+
+import (
+	"reflect"
+
+	"bitbucket.org/toggly/toggly-server/models"
+	dbStore "github.com/nodely/go-mongo-store"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/x/bsonx"
+)
+
+type mgoUser struct {
+	Storage *dbStore.DbStorage
+	CRUD    dbStore.CRUD
+}
+
+func (a *mgoUser) Get(id primitive.ObjectID) *models.User {
+	var data models.User
+	if err := a.CRUD.FindOne(bson.M{"_id": id}).Decode(&data); err != nil {
+		return nil
+	}
+	return &data
+}
+
+func (a *mgoUser) FindByEmail(email string) *models.User {
+	var data models.User
+	if err := a.CRUD.FindOne(bson.M{"email": email}).Decode(&data); err != nil {
+		return nil
+	}
+	return &data
+}
+
+func (a *mgoUser) Create(data *models.User) (*models.User, error) {
+	// check index
+	if err := a.ensureIndexes(); err != nil {
+		return nil, err
+	}
+
+	ins, err := a.CRUD.Insert(data)
+	if err != nil {
+		return nil, err
+	}
+	rec, err := a.CRUD.GetItem(ins[0].(primitive.ObjectID), reflect.TypeOf(new(models.User)))
+	if err != nil {
+		return nil, err
+	}
+	return rec.(*models.User), nil
+}
+
+func (a *mgoUser) ensureIndexes() error {
+	return a.CRUD.EnsureIndexesRaw(mongo.IndexModel{
+		Keys: bsonx.Doc{
+			{Key: "email", Value: bsonx.Int32(1)},
+		},
+		Options: options.Index().SetUnique(true),
+	})
+}
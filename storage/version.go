@@ -0,0 +1,117 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"bitbucket.org/toggly/toggly-server/models"
+	dbStore "github.com/nodely/go-mongo-store"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/x/bsonx"
+)
+
+type mgoVersion struct {
+	Storage *dbStore.DbStorage
+	CRUD    dbStore.CRUD
+}
+
+func (a *mgoVersion) List(owner string, envID primitive.ObjectID, code string) []*models.ParameterVersion {
+	results := make([]*models.ParameterVersion, 0)
+	cursor, err := a.CRUD.Find(bson.M{"owner_id": owner, "env_id": envID, "code": code}, options.Find().SetSort(bson.D{{"version", -1}}))
+	if err != nil {
+		fmt.Println(err.Error())
+		return results
+	}
+	for cursor.Next(context.TODO()) {
+		var rec models.ParameterVersion
+		cursor.Decode(&rec)
+		results = append(results, &rec)
+	}
+	return results
+}
+
+func (a *mgoVersion) Get(owner string, envID primitive.ObjectID, code string, version int64) *models.ParameterVersion {
+	var data models.ParameterVersion
+	a.CRUD.FindOne(bson.M{"owner_id": owner, "env_id": envID, "code": code, "version": version}).Decode(&data)
+	return &data
+}
+
+func (a *mgoVersion) Create(data *models.ParameterVersion) (*models.ParameterVersion, error) {
+	// check index
+	if err := a.ensureIndexes(); err != nil {
+		return nil, err
+	}
+
+	ins, err := a.CRUD.Insert(data)
+	if err != nil {
+		return nil, err
+	}
+	data.ID = ins[0].(primitive.ObjectID)
+	return data, nil
+}
+
+func (a *mgoVersion) DeleteAll(owner string, projectID primitive.ObjectID) error {
+	cursor, err := a.CRUD.Find(bson.M{"owner_id": owner, "project_id": projectID})
+	if err != nil {
+		return err
+	}
+	for cursor.Next(context.TODO()) {
+		var rec models.ParameterVersion
+		if err := cursor.Decode(&rec); err != nil {
+			return err
+		}
+		if err := a.CRUD.DeleteItem(rec.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (a *mgoVersion) DeleteByEnvironment(owner string, envID primitive.ObjectID) error {
+	cursor, err := a.CRUD.Find(bson.M{"owner_id": owner, "env_id": envID})
+	if err != nil {
+		return err
+	}
+	for cursor.Next(context.TODO()) {
+		var rec models.ParameterVersion
+		if err := cursor.Decode(&rec); err != nil {
+			return err
+		}
+		if err := a.CRUD.DeleteItem(rec.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (a *mgoVersion) Last(owner string, envID primitive.ObjectID, code string) int64 {
+	cursor, err := a.CRUD.Find(bson.M{"owner_id": owner, "env_id": envID, "code": code}, options.Find().SetSort(bson.D{{"version", -1}}).SetLimit(1))
+	if err != nil {
+		fmt.Println(err.Error())
+		return 0
+	}
+	var rec models.ParameterVersion
+	if cursor.Next(context.TODO()) {
+		cursor.Decode(&rec)
+	}
+	return rec.Version
+}
+
+func (a *mgoVersion) IsExist(owner string, envID primitive.ObjectID, code string, version int64) bool {
+	return a.CRUD.Count(bson.M{"owner_id": owner, "env_id": envID, "code": code, "version": version}) != 0
+}
+
+func (a *mgoVersion) ensureIndexes() error {
+	return a.CRUD.EnsureIndexesRaw(mongo.IndexModel{
+		Keys: bsonx.Doc{
+			{Key: "owner_id", Value: bsonx.Int32(1)},
+			{Key: "env_id", Value: bsonx.Int32(1)},
+			{Key: "code", Value: bsonx.Int32(1)},
+			{Key: "version", Value: bsonx.Int32(1)},
+		},
+		Options: options.Index().SetUnique(true),
+	})
+}